@@ -0,0 +1,271 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy controls what an [AsyncHandler] does when its queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until there is room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the record being handled.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest record in the queue.
+	OverflowDropOldest
+	// OverflowDropBelow drops the record being handled if its level is below
+	// [AsyncOptions.DropBelow], and waits otherwise.
+	OverflowDropBelow
+)
+
+// defaultQueueSize is the queue size used if [AsyncOptions.QueueSize] is not set.
+const defaultQueueSize = 1024
+
+// AsyncOptions are options for an [AsyncHandler].
+// A zero AsyncOptions consists entirely of default values.
+type AsyncOptions struct {
+	// QueueSize is the maximum number of records waiting to be written.
+	// Defaults to 1024.
+	QueueSize int
+
+	// Overflow is the policy used when the queue is full.
+	// By default, Handle waits until there is room in the queue.
+	Overflow OverflowPolicy
+
+	// DropBelow is the level below which records are dropped when using
+	// [OverflowDropBelow]. Defaults to LevelWarn.
+	DropBelow slog.Leveler
+
+	// OnError, if set, is called with any error returned by the inner handler.
+	OnError func(error)
+}
+
+// AsyncHandler is a slog.Handler that writes records to an inner handler on
+// a background goroutine, so callers don't wait for slow writers.
+//
+// Records are queued with a [Snapshot] of their context, so context values
+// are the ones at the time the record was logged.
+// Use Flush to wait for queued records to be written, and Close to stop the
+// background goroutine.
+type AsyncHandler struct {
+	h slog.Handler
+	q *asyncQueue
+}
+
+// asyncQueue is the state shared by an AsyncHandler and the handlers derived from it.
+type asyncQueue struct {
+	h    slog.Handler
+	opts AsyncOptions
+	ch   chan asyncEntry
+	done chan struct{}
+
+	// closing is closed when Close is called, to stop waiting to send on ch.
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	// closeMu guards sends on ch against it being closed.
+	// It is never held while waiting to send.
+	closeMu sync.RWMutex
+	closed  bool
+
+	dropped  atomic.Uint64
+	enqueued atomic.Uint64
+
+	// mu guards processed, which counts the records written or dropped after being queued.
+	mu        sync.Mutex
+	cond      *sync.Cond
+	processed uint64
+}
+
+type asyncEntry struct {
+	ctx context.Context
+	h   slog.Handler
+	r   slog.Record
+}
+
+// NewAsyncHandler returns a handler that writes records to h on a background goroutine.
+// If opts is nil, the default options are used.
+func NewAsyncHandler(h slog.Handler, opts *AsyncOptions) *AsyncHandler {
+	if opts == nil {
+		opts = &AsyncOptions{}
+	}
+	size := opts.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	q := &asyncQueue{
+		h:       h,
+		opts:    *opts,
+		ch:      make(chan asyncEntry, size),
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	go q.run()
+	return &AsyncHandler{h: h, q: q}
+}
+
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	e := asyncEntry{ctx: Snapshot(ctx), h: h.h, r: snapshotRecord(r)}
+
+	h.q.closeMu.RLock()
+	queued := !h.q.closed && h.q.enqueue(e)
+	h.q.closeMu.RUnlock()
+	if !queued {
+		// Don't lose records logged during shutdown.
+		return h.h.Handle(e.ctx, e.r)
+	}
+	return nil
+}
+
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{h: h.h.WithAttrs(attrs), q: h.q}
+}
+
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{h: h.h.WithGroup(name), q: h.q}
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *AsyncHandler) Dropped() uint64 {
+	return h.q.dropped.Load()
+}
+
+// Flush implements [Flusher] by waiting for the records queued so far to be
+// written, then flushing the inner handler.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	if err := h.q.wait(ctx); err != nil {
+		return err
+	}
+	return Flush(ctx, h.q.h)
+}
+
+// Close implements [Closer] by writing any queued records, stopping the
+// background goroutine and closing the inner handler.
+// Records handled after Close are written synchronously.
+func (h *AsyncHandler) Close(ctx context.Context) error {
+	// Stop callers waiting for room in the queue, so they release closeMu.
+	h.q.closeOnce.Do(func() { close(h.q.closing) })
+	h.q.closeMu.Lock()
+	if !h.q.closed {
+		h.q.closed = true
+		close(h.q.ch)
+	}
+	h.q.closeMu.Unlock()
+
+	select {
+	case <-h.q.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return Close(ctx, h.q.h)
+}
+
+// enqueue adds e to the queue, applying the overflow policy if it is full.
+// It reports false if the queue is closing before there is room for e,
+// in which case the caller should handle e itself.
+func (q *asyncQueue) enqueue(e asyncEntry) bool {
+	q.enqueued.Add(1)
+	select {
+	case q.ch <- e:
+		return true
+	default:
+	}
+
+	switch q.opts.Overflow {
+	case OverflowDropNewest:
+		q.drop()
+		return true
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.ch <- e:
+				return true
+			default:
+			}
+			select {
+			case <-q.ch:
+				q.drop()
+			default:
+			}
+		}
+	case OverflowDropBelow:
+		level := LevelWarn
+		if q.opts.DropBelow != nil {
+			level = q.opts.DropBelow.Level()
+		}
+		if e.r.Level < level {
+			q.drop()
+			return true
+		}
+	}
+	select {
+	case q.ch <- e:
+		return true
+	case <-q.closing:
+		q.done1()
+		return false
+	}
+}
+
+// drop records that a queued record was dropped.
+func (q *asyncQueue) drop() {
+	q.dropped.Add(1)
+	q.done1()
+}
+
+// done1 records that a queued record was written or dropped.
+func (q *asyncQueue) done1() {
+	q.mu.Lock()
+	q.processed++
+	q.cond.Broadcast()
+	q.mu.Unlock()
+}
+
+// run writes queued records until the queue is closed.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	for e := range q.ch {
+		if err := e.h.Handle(e.ctx, e.r); err != nil && q.opts.OnError != nil {
+			q.opts.OnError(err)
+		}
+		q.done1()
+	}
+}
+
+// wait waits until the records queued so far have been written or dropped.
+func (q *asyncQueue) wait(ctx context.Context) error {
+	target := q.enqueued.Load()
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	})
+	defer stop()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.processed < target {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.cond.Wait()
+	}
+	return nil
+}
+
+// snapshotRecord returns a copy of r that is safe to handle later,
+// with any [slog.LogValuer] attrs resolved.
+func snapshotRecord(r slog.Record) slog.Record {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(resolve(recordAttrs(r))...)
+	return nr
+}
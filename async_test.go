@@ -0,0 +1,182 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingHandler is a handler that waits for unblock to be closed before handling records.
+type blockingHandler struct {
+	slog.Handler
+	unblock chan struct{}
+}
+
+func (h blockingHandler) Handle(ctx context.Context, r slog.Record) error {
+	<-h.unblock
+	return h.Handler.Handle(ctx, r)
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestAsyncHandler(t *testing.T) {
+	b := new(syncBuffer)
+	h := NewAsyncHandler(slog.NewTextHandler(b, testopts), nil)
+	log := New(h)
+
+	ctx, cancel := context.WithCancel(WithValues(context.Background(), "a", "b"))
+	log.InfoContext(ctx, "one")
+	log.With("c", "d").InfoContext(ctx, "two")
+	// The context values are captured when the record is handled.
+	cancel()
+	log.InfoContext(WithValues(ctx, "a", "c"), "three")
+
+	if err := h.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := "level=INFO msg=one a=b\nlevel=INFO msg=two c=d a=b\nlevel=INFO msg=three a=c\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	if err := h.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Records handled after Close are written synchronously.
+	log.Info("four")
+	if got, want := b.String(), want+"level=INFO msg=four\n"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestAsyncHandlerOverflow(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		opts        *AsyncOptions
+		wantDropped uint64
+		want        string
+	}{
+		{"drop newest", &AsyncOptions{QueueSize: 2, Overflow: OverflowDropNewest}, 2,
+			"level=INFO msg=0\nlevel=INFO msg=1\nlevel=WARN msg=2\n"},
+		{"drop oldest", &AsyncOptions{QueueSize: 2, Overflow: OverflowDropOldest}, 2,
+			"level=INFO msg=0\nlevel=INFO msg=3\nlevel=WARN msg=4\n"},
+		{"drop below", &AsyncOptions{QueueSize: 2, Overflow: OverflowDropBelow}, 1,
+			"level=INFO msg=0\nlevel=INFO msg=1\nlevel=WARN msg=2\nlevel=WARN msg=4\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(syncBuffer)
+			unblock := make(chan struct{})
+			h := NewAsyncHandler(blockingHandler{slog.NewTextHandler(b, testopts), unblock}, tc.opts)
+			log := New(h)
+
+			// The first record is picked up by the background goroutine,
+			// which then blocks, so the queue fills up.
+			log.Info("0")
+			waitFor(t, func() bool { return len(h.q.ch) == 0 })
+			log.Info("1")
+			log.Warn("2")
+			log.Info("3")
+
+			// With OverflowDropBelow, warnings wait for room in the queue.
+			time.AfterFunc(10*time.Millisecond, func() { close(unblock) })
+			log.Warn("4")
+
+			if err := h.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := h.Dropped(); got != tc.wantDropped {
+				t.Errorf("want %d dropped, got %d", tc.wantDropped, got)
+			}
+			if got := b.String(); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAsyncHandlerFlushTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	h := NewAsyncHandler(blockingHandler{slog.NewTextHandler(io.Discard, nil), unblock}, nil)
+	New(h).Info("hello")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := h.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+// waitFor waits for cond to be true.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for range 1000 {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("timed out waiting for condition")
+}
+
+func TestAsyncHandlerCloseBlocked(t *testing.T) {
+	b := new(syncBuffer)
+	unblock := make(chan struct{})
+	h := NewAsyncHandler(blockingHandler{Handler: slog.NewTextHandler(b, testopts), unblock: unblock}, &AsyncOptions{
+		QueueSize: 1,
+	})
+	log := New(h)
+
+	log.Info("0")
+	waitFor(t, func() bool { return len(h.q.ch) == 0 })
+	log.Info("1")
+	// The queue is full, so this waits for room.
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		log.Info("2")
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := h.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("want Close to respect its deadline, took %v", d)
+	}
+
+	close(unblock)
+	<-logged
+	if err := h.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"msg=0", "msg=1", "msg=2"} {
+		if !strings.Contains(b.String(), msg) {
+			t.Errorf("want %s written, got %q", msg, b.String())
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package clog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// defaultBufferSize is the number of records kept if [BufferOptions.Size] is not set.
+const defaultBufferSize = 1000
+
+type bufferKey struct{}
+
+// BufferOptions are options for a [Buffer].
+// A zero BufferOptions consists entirely of default values.
+type BufferOptions struct {
+	// Size is the maximum number of records kept. When the buffer is full,
+	// the oldest record is dropped. Defaults to 1000.
+	Size int
+
+	// Level is the minimum level of records kept.
+	// By default, records at all levels are kept.
+	Level slog.Leveler
+}
+
+// Buffer holds the records logged with a context returned by [WithBuffer]
+// until the caller decides whether to write them, e.g. at the end of a
+// request. Records are only buffered by a [Handler].
+//
+// Once Emit, EmitEnabled or Discard has been called, records logged with
+// the context are written as usual.
+type Buffer struct {
+	opts BufferOptions
+
+	mu      sync.Mutex
+	entries []bufferEntry
+	dropped int
+	done    bool
+}
+
+type bufferEntry struct {
+	ctx context.Context
+	h   slog.Handler
+	r   slog.Record
+	// enabled is whether h was enabled for the record when it was logged.
+	enabled bool
+}
+
+// WithBuffer returns a context that buffers the records logged with it,
+// and the Buffer that holds them.
+func WithBuffer(ctx context.Context) (context.Context, *Buffer) {
+	return WithBufferOptions(ctx, nil)
+}
+
+// WithBufferOptions is like [WithBuffer], using the given options.
+// If opts is nil, the default options are used.
+func WithBufferOptions(ctx context.Context, opts *BufferOptions) (context.Context, *Buffer) {
+	if opts == nil {
+		opts = &BufferOptions{}
+	}
+	b := &Buffer{opts: *opts}
+	return context.WithValue(ctx, bufferKey{}, b), b
+}
+
+// bufferFromContext returns the Buffer set with [WithBuffer], if any.
+func bufferFromContext(ctx context.Context) *Buffer {
+	b, _ := ctx.Value(bufferKey{}).(*Buffer)
+	return b
+}
+
+// buffering reports whether a [Handler] would buffer records at level logged with ctx.
+func buffering(ctx context.Context, level slog.Level) bool {
+	b := bufferFromContext(ctx)
+	return b != nil && b.accepts(level)
+}
+
+// Emit writes all of the buffered records, regardless of level.
+func (b *Buffer) Emit() error {
+	return b.emit(false)
+}
+
+// EmitEnabled writes the buffered records that their handler was enabled for
+// when they were logged, and drops the rest. This is what would have been
+// written without the buffer.
+func (b *Buffer) EmitEnabled() error {
+	return b.emit(true)
+}
+
+// Discard drops all of the buffered records.
+func (b *Buffer) Discard() {
+	b.take()
+}
+
+// Len returns the number of buffered records.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+// Dropped returns the number of records dropped because the buffer was full.
+func (b *Buffer) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// accepts reports whether records at level would be buffered.
+func (b *Buffer) accepts(level slog.Level) bool {
+	if b.opts.Level != nil && level < b.opts.Level.Level() {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.done
+}
+
+// add buffers r, to be written to h later, and reports whether it was buffered.
+func (b *Buffer) add(ctx context.Context, h slog.Handler, r slog.Record, enabled bool) bool {
+	if b.opts.Level != nil && r.Level < b.opts.Level.Level() {
+		return false
+	}
+	e := bufferEntry{ctx: Snapshot(ctx), h: h, r: snapshotRecord(r), enabled: enabled}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return false
+	}
+	size := b.opts.Size
+	if size <= 0 {
+		size = defaultBufferSize
+	}
+	if len(b.entries) >= size {
+		b.entries = b.entries[1:]
+		b.dropped++
+	}
+	b.entries = append(b.entries, e)
+	return true
+}
+
+// take returns the buffered entries and stops buffering.
+func (b *Buffer) take() []bufferEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries := b.entries
+	b.entries = nil
+	b.done = true
+	return entries
+}
+
+func (b *Buffer) emit(onlyEnabled bool) error {
+	var errs []error
+	for _, e := range b.take() {
+		if onlyEnabled && !e.enabled {
+			continue
+		}
+		if err := e.h.Handle(e.ctx, e.r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
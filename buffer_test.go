@@ -0,0 +1,113 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		end  func(*Buffer) error
+		want string
+	}{{
+		name: "emit",
+		end:  (*Buffer).Emit,
+		want: "level=DEBUG msg=one a=b\nlevel=INFO msg=two c=d a=b\n",
+	}, {
+		name: "emit enabled",
+		end:  (*Buffer).EmitEnabled,
+		want: "level=INFO msg=two c=d a=b\n",
+	}, {
+		name: "discard",
+		end:  func(b *Buffer) error { b.Discard(); return nil },
+		want: "",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			log := New(slog.NewTextHandler(b, testopts))
+
+			ctx, buf := WithBuffer(WithValues(context.Background(), "a", "b"))
+			log.DebugContext(ctx, "one")
+			log.With("c", "d").InfoContext(ctx, "two")
+			if got := b.String(); got != "" {
+				t.Fatalf("want no output before the buffer ends, got %q", got)
+			}
+			if got := buf.Len(); got != 2 {
+				t.Errorf("want 2 buffered records, got %d", got)
+			}
+
+			if err := tc.end(buf); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+
+			// Records logged after the buffer ends are written as usual.
+			b.Reset()
+			log.DebugContext(ctx, "three")
+			log.InfoContext(ctx, "four")
+			if want, got := "level=INFO msg=four a=b\n", b.String(); got != want {
+				t.Errorf("want %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestBufferOptions(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := New(slog.NewTextHandler(b, testopts))
+
+	ctx, buf := WithBufferOptions(context.Background(), &BufferOptions{
+		Size:  2,
+		Level: slog.LevelDebug,
+	})
+	log.TraceContext(ctx, "one")
+	log.DebugContext(ctx, "two")
+	log.DebugContext(ctx, "three")
+	log.InfoContext(ctx, "four")
+	if got := buf.Dropped(); got != 1 {
+		t.Errorf("want 1 dropped record, got %d", got)
+	}
+	if err := buf.Emit(); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "level=DEBUG msg=three\nlevel=INFO msg=four\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestBufferNested(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := New(NewHandler(slog.NewTextHandler(b, testopts)))
+
+	ctx, buf := WithBuffer(context.Background())
+	log.DebugContext(ctx, "one")
+	log.InfoContext(ctx, "two")
+	if err := buf.EmitEnabled(); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "level=INFO msg=two\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestBufferOtherHandler(t *testing.T) {
+	// Records are only buffered by a Handler, so other handlers behave as usual.
+	b := new(bytes.Buffer)
+	log := NewLogger(slog.New(slog.NewTextHandler(b, testopts)))
+
+	ctx, buf := WithBuffer(context.Background())
+	log.DebugContext(ctx, "one")
+	log.DebugContextf(ctx, "two")
+	log.InfoContext(ctx, "three")
+	if want, got := "level=INFO msg=three\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got := buf.Len(); got != 0 {
+		t.Errorf("want no buffered records, got %d", got)
+	}
+}
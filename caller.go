@@ -0,0 +1,59 @@
+package clog
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// helpers is the set of function names marked with [Helper].
+	helpers    sync.Map
+	hasHelpers atomic.Bool
+)
+
+// Helper marks the calling function as a logging helper.
+// When recording the source location of a log record, helper functions are
+// skipped, so the location points at the helper's caller instead.
+// This is similar to [testing.T.Helper].
+func Helper() {
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) == 0 { // skip [Callers, Helper]
+		return
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if _, ok := helpers.Load(frame.Function); !ok {
+		helpers.Store(frame.Function, struct{}{})
+		hasHelpers.Store(true)
+	}
+}
+
+// WithCallerSkip returns a copy of the logger that skips an additional n
+// stack frames when recording the source location of a log record.
+// This is useful for functions that wrap a Logger; see also [Helper].
+func (l *Logger) WithCallerSkip(n int) *Logger {
+	c := *l
+	c.skip += n
+	return &c
+}
+
+// callerPC returns the program counter of a caller, skipping any functions
+// marked with [Helper]. The argument skip is the number of stack frames to
+// ascend, with 0 identifying the caller of callerPC.
+func callerPC(skip int) uintptr {
+	if !hasHelpers.Load() {
+		var pcs [1]uintptr
+		runtime.Callers(skip+2, pcs[:]) // skip [Callers, callerPC]
+		return pcs[0]
+	}
+
+	var pcs [64]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	for _, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if _, ok := helpers.Load(frame.Function); !ok {
+			return pc
+		}
+	}
+	return 0
+}
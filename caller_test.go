@@ -0,0 +1,60 @@
+package clog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+//go:noinline
+func logWithSkip(l *Logger) {
+	l.WithCallerSkip(1).Info("hello")
+}
+
+//go:noinline
+func logWithHelper(l *Logger) {
+	Helper()
+	l.Infof("hello %s", "world")
+}
+
+//go:noinline
+func logWithNestedHelper(l *Logger) {
+	Helper()
+	logWithHelper(l)
+}
+
+func TestCallerSkip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func(*Logger)
+	}{
+		{"WithCallerSkip", logWithSkip},
+		{"Helper", logWithHelper},
+		{"nested Helper", logWithNestedHelper},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			log := New(slog.NewJSONHandler(b, &slog.HandlerOptions{
+				AddSource:   true,
+				ReplaceAttr: testopts.ReplaceAttr,
+			}))
+
+			tc.fn(log)
+
+			var got struct {
+				Source struct {
+					Function string `json:"function"`
+				} `json:"source"`
+			}
+			if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			// The source is the subtest that called the helper.
+			want := "github.com/chainguard-dev/clog.TestCallerSkip.func1"
+			if got.Source.Function != want {
+				t.Errorf("want %v, got %v", want, got.Source.Function)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package clog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultDedupWindow is the window used if [DeduperOptions.Window] is not set.
+	defaultDedupWindow = 10 * time.Second
+
+	// defaultDedupMaxEntries is the limit used if [DeduperOptions.MaxEntries] is not set.
+	defaultDedupMaxEntries = 1000
+)
+
+// DeduperOptions are options for a [Deduper].
+// A zero DeduperOptions consists entirely of default values.
+type DeduperOptions struct {
+	// Window is how long identical records are suppressed after the first
+	// one is written. Defaults to 10 seconds.
+	// Windows are ended by a periodic sweep, so they can last up to a quarter
+	// longer.
+	Window time.Duration
+
+	// MaxEntries is the maximum number of distinct records tracked at once.
+	// While that many are tracked, new distinct records are written without
+	// being tracked, so their duplicates aren't suppressed. Defaults to 1000.
+	MaxEntries int
+
+	// IgnoreKeys are attribute keys that are not compared, such as a
+	// timestamp or attempt number. Keys in groups are joined with dots,
+	// e.g. "request.id".
+	IgnoreKeys []string
+
+	// OnError, if set, is called with any error returned by the inner handler
+	// when writing a summary after the window ends.
+	OnError func(error)
+}
+
+// Deduper is a slog.Handler that suppresses identical records: records with
+// the same level, message and attributes as one written within the window.
+//
+// When the window ends, or the handler is flushed, a summary record is
+// written for records that were suppressed. It is a copy of the first record
+// with the attributes "repeated" (the number of records suppressed),
+// "first_seen" and "last_seen".
+type Deduper struct {
+	h slog.Handler
+	d *deduper
+
+	// scope identifies the attrs and groups applied to h, and groups is the
+	// current group path.
+	scope  string
+	groups []string
+}
+
+// deduper is the state shared by a Deduper and the handlers derived from it.
+type deduper struct {
+	h    slog.Handler
+	opts DeduperOptions
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+	// timer runs the next sweep, if any entries are tracked.
+	timer *time.Timer
+}
+
+type dedupEntry struct {
+	ctx     context.Context
+	h       slog.Handler
+	r       slog.Record
+	expires time.Time
+
+	// count is the number of records suppressed since the last summary,
+	// and last is the time of the latest one.
+	count int
+	last  time.Time
+}
+
+// NewDeduper returns a handler that writes records to h, suppressing duplicates.
+// If opts is nil, the default options are used.
+func NewDeduper(h slog.Handler, opts *DeduperOptions) *Deduper {
+	if opts == nil {
+		opts = &DeduperOptions{}
+	}
+	return &Deduper{h: h, d: &deduper{
+		h:       h,
+		opts:    *opts,
+		entries: map[string]*dedupEntry{},
+	}}
+}
+
+func (h *Deduper) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+func (h *Deduper) Handle(ctx context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(h.scope)
+	b.WriteString(r.Level.String())
+	b.WriteByte(0)
+	b.WriteString(r.Message)
+	b.WriteByte(0)
+	h.d.writeAttrs(&b, h.groups, recordAttrs(r))
+	key := b.String()
+
+	h.d.mu.Lock()
+	if e, ok := h.d.entries[key]; ok {
+		e.count++
+		e.last = recordTime(r)
+		h.d.mu.Unlock()
+		return nil
+	}
+	maxEntries := h.d.opts.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultDedupMaxEntries
+	}
+	if len(h.d.entries) < maxEntries {
+		h.d.entries[key] = &dedupEntry{
+			ctx:     Snapshot(ctx),
+			h:       h.h,
+			r:       snapshotRecord(r),
+			expires: time.Now().Add(h.d.window()),
+		}
+		h.d.schedule()
+	}
+	h.d.mu.Unlock()
+
+	return h.h.Handle(ctx, r)
+}
+
+func (h *Deduper) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.scope)
+	h.d.writeAttrs(&b, h.groups, attrs)
+	return &Deduper{h: h.h.WithAttrs(attrs), d: h.d, scope: b.String(), groups: h.groups}
+}
+
+func (h *Deduper) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Deduper{
+		h:      h.h.WithGroup(name),
+		d:      h.d,
+		scope:  h.scope + name + "{",
+		groups: append(slices.Clip(h.groups), name),
+	}
+}
+
+// Flush implements [Flusher] by writing a summary of the records suppressed
+// so far, then flushing the inner handler.
+func (h *Deduper) Flush(ctx context.Context) error {
+	return errors.Join(h.d.summarize(func(*dedupEntry) (bool, bool) { return true, false }), Flush(ctx, h.d.h))
+}
+
+// Close implements [Closer] by writing a summary of the records suppressed
+// so far, then closing the inner handler.
+func (h *Deduper) Close(ctx context.Context) error {
+	return errors.Join(h.d.summarize(func(*dedupEntry) (bool, bool) { return true, true }), Close(ctx, h.d.h))
+}
+
+// writeAttrs writes attrs to b for comparison, skipping ignored keys.
+func (d *deduper) writeAttrs(b *strings.Builder, groups []string, attrs []slog.Attr) {
+	for _, a := range attrs {
+		path := append(slices.Clip(groups), a.Key)
+		if slices.Contains(d.opts.IgnoreKeys, strings.Join(path, ".")) {
+			continue
+		}
+		v := a.Value.Resolve()
+		b.WriteString(strconv.Quote(a.Key))
+		if v.Kind() == slog.KindGroup {
+			b.WriteByte('{')
+			d.writeAttrs(b, path, v.Group())
+			b.WriteByte('}')
+			continue
+		}
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(v.String()))
+	}
+}
+
+func (d *deduper) window() time.Duration {
+	if d.opts.Window <= 0 {
+		return defaultDedupWindow
+	}
+	return d.opts.Window
+}
+
+// schedule starts the timer for the next sweep if entries are tracked,
+// and stops it if not. d.mu must be held.
+func (d *deduper) schedule() {
+	switch {
+	case d.timer == nil && len(d.entries) > 0:
+		d.timer = time.AfterFunc(d.window()/4, d.sweep)
+	case d.timer != nil && len(d.entries) == 0:
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
+
+// sweep ends the windows that have expired, writing summaries if needed.
+func (d *deduper) sweep() {
+	d.mu.Lock()
+	d.timer = nil
+	d.mu.Unlock()
+
+	now := time.Now()
+	err := d.summarize(func(e *dedupEntry) (bool, bool) {
+		expired := !now.Before(e.expires)
+		return expired, expired
+	})
+	if err != nil && d.opts.OnError != nil {
+		d.opts.OnError(err)
+	}
+}
+
+// summarize writes a summary for each entry with suppressed records for
+// which f reports true, then resets its count. Entries for which f's second
+// result is true have their windows ended.
+func (d *deduper) summarize(f func(*dedupEntry) (summarize, end bool)) error {
+	type summary struct {
+		e     *dedupEntry
+		count int
+		last  time.Time
+	}
+	var summaries []summary
+
+	d.mu.Lock()
+	for key, e := range d.entries {
+		sum, end := f(e)
+		if sum && e.count > 0 {
+			summaries = append(summaries, summary{e: e, count: e.count, last: e.last})
+			e.count = 0
+		}
+		if end {
+			delete(d.entries, key)
+		}
+	}
+	d.schedule()
+	d.mu.Unlock()
+
+	slices.SortFunc(summaries, func(a, b summary) int { return a.e.r.Time.Compare(b.e.r.Time) })
+	var errs []error
+	for _, s := range summaries {
+		if err := s.e.summary(s.count, s.last); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// summary writes a summary of count suppressed records, the latest at last.
+func (e *dedupEntry) summary(count int, last time.Time) error {
+	r := e.r.Clone()
+	r.Time = last
+	r.AddAttrs(
+		slog.Int("repeated", count),
+		slog.Time("first_seen", e.r.Time),
+		slog.Time("last_seen", last),
+	)
+	return e.h.Handle(e.ctx, r)
+}
+
+// recordTime returns the time of r, or the current time if it isn't set.
+func recordTime(r slog.Record) time.Time {
+	if r.Time.IsZero() {
+		return time.Now()
+	}
+	return r.Time
+}
@@ -0,0 +1,132 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeduper(t *testing.T) {
+	b := new(syncBuffer)
+	h := NewDeduper(slog.NewTextHandler(b, testopts), &DeduperOptions{
+		Window:     time.Hour,
+		IgnoreKeys: []string{"attempt", "g.n"},
+	})
+	ctx := context.Background()
+	start := time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC)
+	handle := func(h slog.Handler, i int, level slog.Level, msg string, attrs ...slog.Attr) {
+		t.Helper()
+		r := slog.NewRecord(start.Add(time.Duration(i)*time.Second), level, msg, 0)
+		r.AddAttrs(attrs...)
+		if err := h.Handle(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handle(h, 0, slog.LevelWarn, "retrying", slog.String("err", "timeout"), slog.Int("attempt", 1))
+	handle(h, 1, slog.LevelWarn, "retrying", slog.String("err", "timeout"), slog.Int("attempt", 2))
+	handle(h, 2, slog.LevelWarn, "retrying", slog.String("err", "timeout"), slog.Int("attempt", 3))
+	// Different attrs, level or handler attrs are not duplicates.
+	handle(h, 3, slog.LevelWarn, "retrying", slog.String("err", "refused"))
+	handle(h, 4, slog.LevelError, "retrying", slog.String("err", "timeout"))
+	handle(h.WithAttrs([]slog.Attr{slog.String("a", "b")}), 5, slog.LevelWarn, "retrying", slog.String("err", "timeout"), slog.Int("attempt", 4))
+	// Ignored keys in groups.
+	g := h.WithGroup("g")
+	handle(g, 6, slog.LevelInfo, "group", slog.Int("n", 1))
+	handle(g, 7, slog.LevelInfo, "group", slog.Int("n", 2))
+
+	want := []string{
+		"level=WARN msg=retrying err=timeout attempt=1",
+		"level=WARN msg=retrying err=refused",
+		"level=ERROR msg=retrying err=timeout",
+		"level=WARN msg=retrying a=b err=timeout attempt=4",
+		"level=INFO msg=group g.n=1",
+	}
+	if got := strings.Split(strings.TrimSpace(b.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	b.b.Reset()
+	if err := h.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"level=WARN msg=retrying err=timeout attempt=1 repeated=2 first_seen=2009-11-10T23:00:00.000Z last_seen=2009-11-10T23:00:02.000Z",
+		"level=INFO msg=group g.n=1 g.repeated=1 g.first_seen=2009-11-10T23:00:06.000Z g.last_seen=2009-11-10T23:00:07.000Z",
+	}
+	if got := strings.Split(strings.TrimSpace(b.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	// Records are still suppressed after a flush, until the window ends.
+	b.b.Reset()
+	handle(h, 8, slog.LevelWarn, "retrying", slog.String("err", "timeout"), slog.Int("attempt", 5))
+	if got := b.String(); got != "" {
+		t.Errorf("want no output, got %q", got)
+	}
+	if err := h.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"level=WARN msg=retrying err=timeout attempt=1 repeated=1 first_seen=2009-11-10T23:00:00.000Z last_seen=2009-11-10T23:00:08.000Z",
+	}
+	if got := strings.Split(strings.TrimSpace(b.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestDeduperWindow(t *testing.T) {
+	b := new(syncBuffer)
+	log := New(NewDeduper(slog.NewTextHandler(b, testopts), &DeduperOptions{
+		Window: 10 * time.Millisecond,
+	}))
+	for range 3 {
+		log.Info("hello")
+	}
+	waitFor(t, func() bool { return strings.Contains(b.String(), "repeated=2") })
+
+	log.Info("hello")
+	if got := strings.Count(b.String(), "msg=hello"); got != 3 {
+		t.Errorf("want 3 records after the window ends, got %d: %q", got, b.String())
+	}
+}
+
+func TestDeduperMaxEntries(t *testing.T) {
+	b := new(syncBuffer)
+	h := NewDeduper(slog.NewTextHandler(b, testopts), &DeduperOptions{
+		Window:     time.Hour,
+		MaxEntries: 1,
+	})
+	log := New(h)
+	log.Info("a")
+	log.Info("a")
+	// Untracked, so not suppressed.
+	log.Info("b")
+	log.Info("b")
+
+	if want, got := "level=INFO msg=a\nlevel=INFO msg=b\nlevel=INFO msg=b\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	h.d.mu.Lock()
+	if got := len(h.d.entries); got != 1 {
+		t.Errorf("want 1 entry tracked, got %d", got)
+	}
+	if h.d.timer == nil {
+		t.Error("want a sweep scheduled")
+	}
+	h.d.mu.Unlock()
+
+	if err := h.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	h.d.mu.Lock()
+	defer h.d.mu.Unlock()
+	if h.d.timer != nil {
+		t.Error("want no sweep scheduled after Close")
+	}
+	if !strings.Contains(b.String(), "msg=a repeated=1") {
+		t.Errorf("want a summary of a, got %q", b.String())
+	}
+}
@@ -19,6 +19,9 @@ func main() {
 	// Use slog package directly
 	slog.InfoContext(ctx, "hello world", slog.Bool("baz", true))
 
-	// glog / zap style (note: can't pass additional attributes)
+	// glog / zap style
 	clog.Errorf("hello %s", "world")
+
+	// Trailing slog.Attr arguments are added as attributes, not formatted
+	clog.Errorf("hello %s", "world", slog.Int("attempt", 2))
 }
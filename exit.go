@@ -0,0 +1,95 @@
+package clog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// ExitFunc is called by Fatal and friends after logging, with the exit code.
+type ExitFunc func(code int)
+
+var (
+	exitFunc atomic.Pointer[ExitFunc]
+	exitCode atomic.Int64
+)
+
+func init() {
+	exitCode.Store(1)
+}
+
+// flushTimeout bounds how long Fatal and friends wait for the handler to flush.
+const flushTimeout = 5 * time.Second
+
+// SetExitFunc sets the function called by Fatal and friends after logging,
+// for loggers without their own exit function (see [Logger.WithExitFunc]).
+// If f is nil, [os.Exit] is used.
+// If f returns, so does the Fatal call.
+func SetExitFunc(f ExitFunc) {
+	if f == nil {
+		exitFunc.Store(nil)
+		return
+	}
+	exitFunc.Store(&f)
+}
+
+// SetExitCode sets the exit code used by Fatal and friends,
+// for loggers without their own exit code (see [Logger.WithExitCode]).
+// The default is 1.
+func SetExitCode(code int) {
+	exitCode.Store(int64(code))
+}
+
+// WithExitFunc returns a copy of the logger that calls f instead of exiting
+// after logging with Fatal and friends.
+// If f returns, so does the Fatal call.
+func (l *Logger) WithExitFunc(f ExitFunc) *Logger {
+	c := *l
+	c.exitFunc = f
+	return &c
+}
+
+// WithExitCode returns a copy of the logger that uses the given exit code
+// after logging with Fatal and friends.
+func (l *Logger) WithExitCode(code int) *Logger {
+	c := *l
+	c.exitCode = &code
+	return &c
+}
+
+// Exit is the value [PanicExit] panics with.
+// [Recover] and [RecoverAndRepanic] don't recover it, so it can be caught
+// further up the stack, e.g. by slogtest.CatchFatal.
+type Exit struct {
+	Code int
+}
+
+func (e Exit) String() string {
+	return fmt.Sprintf("clog: Fatal called with exit code %d", e.Code)
+}
+
+// PanicExit is an [ExitFunc] that panics with [Exit] instead of exiting,
+// e.g. for tests.
+func PanicExit(code int) { panic(Exit{Code: code}) }
+
+// exit flushes the logger's handler, then calls the exit function.
+func (l *Logger) exit(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), flushTimeout)
+	_ = Flush(ctx, l.Handler())
+	cancel()
+
+	code := int(exitCode.Load())
+	if l.exitCode != nil {
+		code = *l.exitCode
+	}
+	switch f := exitFunc.Load(); {
+	case l.exitFunc != nil:
+		l.exitFunc(code)
+	case f != nil:
+		(*f)(code)
+	default:
+		os.Exit(code)
+	}
+}
@@ -0,0 +1,70 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+// flushHandler is a handler that records whether it was flushed.
+type flushHandler struct {
+	slog.Handler
+	flushed *bool
+}
+
+func (h flushHandler) Flush(context.Context) error {
+	*h.flushed = true
+	return nil
+}
+
+func TestFatal(t *testing.T) {
+	b := new(bytes.Buffer)
+	var flushed bool
+	base := New(flushHandler{slog.NewTextHandler(b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			return ReplaceLevelName(groups, testopts.ReplaceAttr(groups, a))
+		},
+	}), &flushed})
+
+	var got []int
+	log := base.WithExitFunc(func(code int) { got = append(got, code) })
+	ctx := WithLogger(context.Background(), log)
+
+	log.Fatal("a")
+	log.WithExitCode(2).Fatalf("b %d", 1)
+	FatalContext(ctx, "c")
+	FatalContextf(ctx, "d %d", 2)
+
+	if want := []int{1, 2, 1, 1}; !slices.Equal(want, got) {
+		t.Errorf("want exit codes %v, got %v", want, got)
+	}
+	if !flushed {
+		t.Error("want handler to be flushed")
+	}
+	want := `level=FATAL msg=a
+level=FATAL msg="b 1"
+level=FATAL msg=c
+level=FATAL msg="d 2"
+`
+	if b.String() != want {
+		t.Errorf("want %q, got %q", want, b.String())
+	}
+
+	t.Run("global", func(t *testing.T) {
+		t.Cleanup(func() {
+			SetExitFunc(nil)
+			SetExitCode(1)
+		})
+		var got []int
+		SetExitFunc(func(code int) { got = append(got, code) })
+		SetExitCode(4)
+
+		base.Fatal("e")
+		base.WithExitCode(5).Fatal("f")
+		if want := []int{4, 5}; !slices.Equal(want, got) {
+			t.Errorf("want exit codes %v, got %v", want, got)
+		}
+	})
+}
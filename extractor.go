@@ -0,0 +1,98 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Extractor returns attributes to add to a log record from the context,
+// e.g. a trace ID set by middleware or a third-party library.
+type Extractor func(ctx context.Context) []slog.Attr
+
+var (
+	extractorsMu sync.Mutex
+	extractors   atomic.Pointer[[]Extractor]
+)
+
+// RegisterExtractor registers an extractor that is called by every [Handler]
+// for each record it handles.
+// Extracted attributes are added before values set with [WithValues],
+// which take precedence if they have the same key.
+// RegisterExtractor is typically called from an init function.
+func RegisterExtractor(e Extractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	var list []Extractor
+	if old := extractors.Load(); old != nil {
+		list = append(list, *old...)
+	}
+	list = append(list, e)
+	extractors.Store(&list)
+}
+
+// extract returns the attributes from all registered extractors.
+func extract(ctx context.Context) []slog.Attr {
+	list := extractors.Load()
+	if list == nil {
+		return nil
+	}
+	var attrs []slog.Attr
+	for _, e := range *list {
+		attrs = append(attrs, e(ctx)...)
+	}
+	return attrs
+}
+
+// contextAttrs returns the attributes from registered extractors and [WithValues].
+// Lazy values are resolved.
+func contextAttrs(ctx context.Context) []slog.Attr {
+	v := get(ctx)
+	if v != nil && v.snapshot {
+		return v.attrs
+	}
+	values := v.all()
+	if extracted := extract(ctx); len(extracted) > 0 {
+		return resolve(dedup(append(extracted, values...)))
+	}
+	return resolve(values)
+}
+
+// contextValue returns the value of the context value with the given key,
+// as it would be added to a record. Unlike [contextAttrs], only that value is
+// resolved, and extractors are only called if it wasn't set with [WithValues].
+func contextValue(ctx context.Context, key string) (slog.Value, bool) {
+	v := get(ctx)
+	if v != nil && v.snapshot {
+		for _, a := range v.attrs {
+			if a.Key == key {
+				return a.Value, true
+			}
+		}
+		return slog.Value{}, false
+	}
+	for n := v; n != nil; n = n.parent {
+		for _, a := range slices.Backward(n.attrs) {
+			if a.Key != key {
+				continue
+			}
+			if a.Value.Kind() == slog.KindGroup {
+				// Groups with the same key are merged, so look at all of the values.
+				for _, a := range contextAttrs(ctx) {
+					if a.Key == key {
+						return a.Value, true
+					}
+				}
+			}
+			return a.Value.Resolve(), true
+		}
+	}
+	for _, a := range slices.Backward(extract(ctx)) {
+		if a.Key == key {
+			return a.Value.Resolve(), true
+		}
+	}
+	return slog.Value{}, false
+}
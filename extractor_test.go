@@ -0,0 +1,89 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestExtractor(t *testing.T) {
+	old := extractors.Load()
+	t.Cleanup(func() { extractors.Store(old) })
+
+	RegisterExtractor(func(ctx context.Context) []slog.Attr {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []slog.Attr{slog.String("tenant", tenant)}
+		}
+		return nil
+	})
+	RegisterExtractor(func(context.Context) []slog.Attr {
+		return []slog.Attr{slog.String("a", "extracted")}
+	})
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"extracted", ctx, "level=INFO msg=hello tenant=acme a=extracted\n"},
+		{"values win", WithValues(ctx, "a", "b", "c", "d"), "level=INFO msg=hello tenant=acme a=b c=d\n"},
+		{"no tenant", context.Background(), "level=INFO msg=hello a=extracted\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			log := slog.New(NewHandler(slog.NewTextHandler(b, testopts)))
+			log.InfoContext(tc.ctx, "hello")
+			if got := b.String(); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestContextValue(t *testing.T) {
+	old := extractors.Load()
+	t.Cleanup(func() { extractors.Store(old) })
+	extracted := 0
+	RegisterExtractor(func(context.Context) []slog.Attr {
+		extracted++
+		return []slog.Attr{slog.String("trace", "extracted")}
+	})
+
+	computed := 0
+	ctx := WithValues(context.Background(),
+		"expensive", Lazy(func() any { computed++; return "x" }),
+		"tenant", "a",
+		slog.Group("g", "a", 1),
+	)
+	ctx = WithValues(ctx, "tenant", Lazy(func() any { return "b" }), slog.Group("g", "b", 2))
+
+	for _, tc := range []struct {
+		key  string
+		want string
+		ok   bool
+	}{
+		{"tenant", "b", true},
+		{"g", "[a=1 b=2]", true},
+		{"trace", "extracted", true},
+		{"missing", "", false},
+	} {
+		got, ok := contextValue(ctx, tc.key)
+		if ok != tc.ok || (ok && got.String() != tc.want) {
+			t.Errorf("%s: want %q, %v, got %q, %v", tc.key, tc.want, tc.ok, got, ok)
+		}
+		if got, ok := contextValue(Snapshot(ctx), tc.key); ok != tc.ok || (ok && got.String() != tc.want) {
+			t.Errorf("%s: want %q, %v from a snapshot, got %q, %v", tc.key, tc.want, tc.ok, got, ok)
+		}
+	}
+
+	extracted, computed = 0, 0
+	contextValue(ctx, "tenant")
+	if extracted != 0 || computed != 0 {
+		t.Errorf("want no extractors called or values computed, got %d and %d", extracted, computed)
+	}
+}
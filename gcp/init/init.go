@@ -12,7 +12,8 @@ import (
 func init() {
 	level := slog.LevelInfo
 	if e, ok := os.LookupEnv("LOG_LEVEL"); ok {
-		if err := level.UnmarshalText([]byte(e)); err != nil {
+		var err error
+		if level, err = clog.ParseLevel(e); err != nil {
 			clog.Fatalf("slog: invalid log level: %v", err)
 		}
 	}
@@ -5,15 +5,46 @@ import (
 	"io"
 	"log/slog"
 	"os"
+
+	"github.com/chainguard-dev/clog"
 )
 
 // LevelCritical is an extra log level supported by Cloud Logging.
-const LevelCritical = slog.Level(12)
+// It is the same as [clog.LevelCritical].
+const LevelCritical = clog.LevelCritical
+
+// severities maps clog's levels to Cloud Logging severities, in increasing order.
+// See https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#logseverity
+var severities = []struct {
+	level    slog.Level
+	severity string
+}{
+	{clog.LevelDebug, "DEBUG"},
+	{clog.LevelInfo, "INFO"},
+	{clog.LevelNotice, "NOTICE"},
+	{clog.LevelWarn, "WARN"},
+	{clog.LevelError, "ERROR"},
+	{clog.LevelCritical, "CRITICAL"},
+	{clog.LevelAlert, "ALERT"},
+	{clog.LevelEmergency, "EMERGENCY"},
+}
+
+// severity returns the Cloud Logging severity for the level.
+// Levels between clog's levels use the severity of the level below them,
+// and levels below debug (e.g. [clog.LevelTrace]) use "DEBUG".
+func severity(level slog.Level) string {
+	i := len(severities) - 1
+	for i > 0 && level < severities[i].level {
+		i--
+	}
+	return severities[i].severity
+}
 
 // Handler that outputs JSON understood by the structured log agent.
 // See https://cloud.google.com/logging/docs/agent/logging/configuration#special-fields
 type Handler struct {
 	handler slog.Handler
+	w       io.Writer
 }
 
 // NewHandler returns a new Handler that writes to stderr.
@@ -23,7 +54,7 @@ func NewHandler(level slog.Level) *Handler {
 
 // NewHandlerForWriter returns a new Handler that writes to the given writer.
 func NewHandlerForWriter(w io.Writer, level slog.Level) *Handler {
-	return &Handler{handler: slog.NewJSONHandler(w, &slog.HandlerOptions{
+	return &Handler{w: w, handler: slog.NewJSONHandler(w, &slog.HandlerOptions{
 		AddSource: true,
 		Level:     level,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
@@ -33,9 +64,8 @@ func NewHandlerForWriter(w io.Writer, level slog.Level) *Handler {
 				a.Key = "logging.googleapis.com/sourceLocation"
 			} else if a.Key == slog.LevelKey {
 				a.Key = "severity"
-				level, ok := a.Value.Any().(slog.Level)
-				if ok && level == LevelCritical {
-					a.Value = slog.StringValue("CRITICAL")
+				if level, ok := a.Value.Any().(slog.Level); ok {
+					a.Value = slog.StringValue(severity(level))
 				}
 			}
 			return a
@@ -48,20 +78,49 @@ func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
 }
 
 func (h *Handler) Handle(ctx context.Context, rec slog.Record) error {
-	if trace := TraceFromContext(ctx); trace != "" {
+	if attrs := ExtractTrace(ctx); len(attrs) > 0 && !hasAttr(rec, traceKeyName) {
 		rec = rec.Clone()
 		// Add trace ID	to the record so it is correlated with the request log
 		// See https://cloud.google.com/trace/docs/trace-log-integration
-		rec.Add("logging.googleapis.com/trace", slog.StringValue(trace))
+		rec.AddAttrs(attrs...)
 	}
 
 	return h.handler.Handle(ctx, rec)
 }
 
+// hasAttr reports whether rec has a top-level attr with the given key,
+// e.g. because [ExtractTrace] is registered as a [clog.Extractor].
+func hasAttr(rec slog.Record, key string) bool {
+	found := false
+	rec.Attrs(func(a slog.Attr) bool {
+		found = a.Key == key
+		return !found
+	})
+	return found
+}
+
 func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &Handler{handler: h.handler.WithAttrs(attrs)}
+	return &Handler{w: h.w, handler: h.handler.WithAttrs(attrs)}
 }
 
 func (h *Handler) WithGroup(name string) slog.Handler {
-	return &Handler{handler: h.handler.WithGroup(name)}
+	return &Handler{w: h.w, handler: h.handler.WithGroup(name)}
+}
+
+// Flush implements [clog.Flusher] by flushing the inner handler,
+// and the writer if it has a Flush method (e.g. a [bufio.Writer]).
+func (h *Handler) Flush(ctx context.Context) error {
+	if err := clog.Flush(ctx, h.handler); err != nil {
+		return err
+	}
+	if f, ok := h.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close implements [clog.Closer] by flushing the handler.
+// The writer is not closed.
+func (h *Handler) Close(ctx context.Context) error {
+	return h.Flush(ctx)
 }
@@ -1,9 +1,14 @@
 package gcp
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"log/slog"
 	"testing"
+
+	"github.com/chainguard-dev/clog"
 )
 
 func TestHandler(t *testing.T) {
@@ -17,3 +22,72 @@ func TestHandler(t *testing.T) {
 	l.With("level", 123).Log(ctx, slog.LevelInfo, "hello world")
 	l.With("level", map[string]string{}).Log(ctx, slog.LevelInfo, "hello world")
 }
+
+func TestSeverity(t *testing.T) {
+	for _, tc := range []struct {
+		level slog.Level
+		want  string
+	}{
+		{clog.LevelTrace, "DEBUG"},
+		{clog.LevelDebug, "DEBUG"},
+		{clog.LevelInfo, "INFO"},
+		{clog.LevelNotice, "NOTICE"},
+		{clog.LevelWarn, "WARN"},
+		{clog.LevelError, "ERROR"},
+		{clog.LevelError + 1, "ERROR"},
+		{LevelCritical, "CRITICAL"},
+		{clog.LevelFatal, "CRITICAL"},
+		{clog.LevelAlert, "ALERT"},
+		{clog.LevelEmergency, "EMERGENCY"},
+	} {
+		b := new(bytes.Buffer)
+		slog.New(NewHandlerForWriter(b, clog.LevelTrace)).Log(context.Background(), tc.level, "hello")
+		var got struct {
+			Severity string `json:"severity"`
+		}
+		if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Severity != tc.want {
+			t.Errorf("level %v: want %q, got %q", tc.level, tc.want, got.Severity)
+		}
+	}
+}
+
+func TestHandlerFlush(t *testing.T) {
+	b := new(bytes.Buffer)
+	w := bufio.NewWriter(b)
+	log := clog.New(NewHandlerForWriter(w, slog.LevelInfo)).With("a", "b")
+	log.Info("hello")
+	if b.Len() != 0 {
+		t.Fatalf("want buffered output, got %q", b.String())
+	}
+	if err := log.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() == 0 {
+		t.Error("want output after flush")
+	}
+}
+
+func TestHandlerRedact(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := clog.New(clog.Chain(NewHandlerForWriter(b, slog.LevelInfo), clog.Redact(nil)))
+	ctx := clog.WithValues(WithTrace(context.Background(), "projects/p/traces/t"), "token", "abc")
+	log.With("password", "hunter2").InfoContext(ctx, "hello")
+
+	var got map[string]any
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]any{
+		"password":   "[REDACTED]",
+		"token":      "[REDACTED]",
+		traceKeyName: "projects/p/traces/t",
+		"severity":   "INFO",
+	} {
+		if got[k] != want {
+			t.Errorf("%s: want %v, got %v", k, want, got[k])
+		}
+	}
+}
@@ -126,6 +126,20 @@ func TraceFromContext(ctx context.Context) string {
 	return trace.(string)
 }
 
+// traceKeyName is the special field Cloud Logging uses to correlate logs with a trace.
+// See https://cloud.google.com/trace/docs/trace-log-integration
+const traceKeyName = "logging.googleapis.com/trace"
+
+// ExtractTrace returns the trace information from the context as a log attribute.
+// [Handler] adds it automatically; it can be registered with
+// [clog.RegisterExtractor] to add the trace when using other handlers.
+func ExtractTrace(ctx context.Context) []slog.Attr {
+	if trace := TraceFromContext(ctx); trace != "" {
+		return []slog.Attr{slog.String(traceKeyName, trace)}
+	}
+	return nil
+}
+
 func parseTraceFromW3CHeader(traceparent string) string {
 	traceParts := strings.Split(traceparent, "-")
 	if len(traceParts) > 1 {
@@ -1,9 +1,12 @@
 package gcp
 
 import (
+	"bytes"
+	"context"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/chainguard-dev/clog"
@@ -62,3 +65,28 @@ func TestTrace(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractTrace(t *testing.T) {
+	if got := ExtractTrace(t.Context()); got != nil {
+		t.Errorf("want no attrs, got %v", got)
+	}
+
+	ctx := WithTrace(t.Context(), "projects/p/traces/t")
+	got := ExtractTrace(ctx)
+	if len(got) != 1 || got[0].Key != "logging.googleapis.com/trace" || got[0].Value.String() != "projects/p/traces/t" {
+		t.Errorf("want trace attr, got %v", got)
+	}
+}
+
+func TestHandlerTraceOnce(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := clog.New(NewHandlerForWriter(b, slog.LevelInfo))
+	ctx := WithTrace(context.Background(), "projects/p/traces/t")
+	// As if ExtractTrace were registered as an extractor.
+	ctx = clog.WithAttrs(ctx, ExtractTrace(ctx)...)
+	log.InfoContext(ctx, "hello")
+
+	if got := strings.Count(b.String(), traceKeyName); got != 1 {
+		t.Errorf("want the trace once, got %d times: %s", got, b.String())
+	}
+}
@@ -3,6 +3,8 @@ package clog
 import (
 	"context"
 	"log/slog"
+	"slices"
+	"sync"
 )
 
 var (
@@ -10,57 +12,219 @@ var (
 )
 
 type key struct{}
-type ctxVal map[string]any
 
-// With returns a new context with the given values.
-// Values are expected to be key-value pairs, where the key is a string.
-// e.g. WithValues(ctx, "foo", "bar", "baz", 1)
-// If a value already exists, it is overwritten.
-// If an odd number of arguments are provided, With panics.
-func WithValues(ctx context.Context, args ...any) context.Context {
-	if len(args)%2 != 0 {
-		panic("non-even number of arguments")
-	}
+// ctxVal is an immutable node in a chain of context values.
+// Each call to [WithValues] adds a node that points at the values that were
+// already in the context, so adding values never copies the existing ones.
+type ctxVal struct {
+	parent *ctxVal
+	attrs  []slog.Attr
+
+	// snapshot is set for nodes created by [Snapshot], whose attrs already
+	// include the resolved values from the whole chain and any extractors.
+	snapshot bool
 
-	values := ctxVal{}
+	once     sync.Once
+	resolved []slog.Attr
+}
+
+// badKey is the key used by [slog] for malformed key-value pairs.
+const badKey = "!BADKEY"
 
-	// Copy existing values
-	for k, v := range get(ctx) {
-		values[k] = v
+// WithValues returns a new context with the given values.
+// Arguments are converted to attributes the same way [slog.Logger.Log] does:
+// a string key followed by a value, or an [slog.Attr] (including [slog.Group]).
+// e.g. WithValues(ctx, "foo", "bar", slog.Group("request", "id", 1))
+// If a value already exists, it is overwritten, but keeps its original position.
+// Groups with the same key are merged.
+// Malformed arguments are recorded under the key "!BADKEY" rather than panicking.
+func WithValues(ctx context.Context, args ...any) context.Context {
+	return WithAttrs(ctx, argsToAttrs(args)...)
+}
+
+// WithAttrs is a more efficient version of [WithValues] that accepts only [slog.Attr] values.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return ctx
 	}
+	return context.WithValue(ctx, ctxKey, &ctxVal{
+		parent: get(ctx),
+		attrs:  attrs,
+	})
+}
 
-	for i := 0; i < len(args); i++ {
-		key, ok := args[i].(string)
-		if !ok {
-			panic("non-string key")
-		}
-		i++
-		if i >= len(args) {
-			break
+// argsToAttrs converts key-value pairs and attributes to a list of attributes.
+// This mirrors the conversion done by [slog.Record.Add].
+func argsToAttrs(args []any) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(args))
+	for len(args) > 0 {
+		switch x := args[0].(type) {
+		case string:
+			if len(args) == 1 {
+				attrs = append(attrs, slog.String(badKey, x))
+				args = nil
+				continue
+			}
+			attrs = append(attrs, slog.Any(x, args[1]))
+			args = args[2:]
+		case slog.Attr:
+			attrs = append(attrs, x)
+			args = args[1:]
+		default:
+			attrs = append(attrs, slog.Any(badKey, x))
+			args = args[1:]
 		}
-		value := args[i]
-		values[key] = value
 	}
-	return context.WithValue(ctx, ctxKey, values)
+	return attrs
 }
 
-func get(ctx context.Context) ctxVal {
-	if value, ok := ctx.Value(ctxKey).(ctxVal); ok {
+// Snapshot returns a copy of ctx for handling a record later, e.g. on another
+// goroutine. The copy is not canceled when ctx is, and the values from
+// [WithValues] and registered extractors are resolved as of now.
+func Snapshot(ctx context.Context) context.Context {
+	if v := get(ctx); v != nil && v.snapshot {
+		return context.WithoutCancel(ctx)
+	}
+	return context.WithValue(context.WithoutCancel(ctx), ctxKey, &ctxVal{
+		attrs:    contextAttrs(ctx),
+		snapshot: true,
+	})
+}
+
+func get(ctx context.Context) *ctxVal {
+	if value, ok := ctx.Value(ctxKey).(*ctxVal); ok {
 		return value
 	}
 	return nil
 }
 
+// all returns the values of the chain in insertion order.
+// Later values shadow earlier values with the same key.
+// The result is computed once per node and must not be modified.
+func (v *ctxVal) all() []slog.Attr {
+	if v == nil {
+		return nil
+	}
+	v.once.Do(func() {
+		var chain []*ctxVal
+		for n := v; n != nil; n = n.parent {
+			chain = append(chain, n)
+		}
+		var attrs []slog.Attr
+		for i := len(chain) - 1; i >= 0; i-- {
+			attrs = append(attrs, chain[i].attrs...)
+		}
+		v.resolved = dedup(attrs)
+	})
+	return v.resolved
+}
+
+// dedup removes attrs whose key is repeated later in the list.
+// The remaining attr keeps the position of the first occurrence.
+// If both attrs are groups, their attrs are merged instead.
+// Malformed attrs are never removed.
+func dedup(attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, 0, len(attrs))
+	index := make(map[string]int, len(attrs))
+	for _, a := range attrs {
+		if a.Key == badKey {
+			out = append(out, a)
+			continue
+		}
+		if i, ok := index[a.Key]; ok {
+			if prev := out[i]; prev.Value.Kind() == slog.KindGroup && a.Value.Kind() == slog.KindGroup {
+				merged := append(append([]slog.Attr{}, prev.Value.Group()...), a.Value.Group()...)
+				a.Value = slog.GroupValue(dedup(merged)...)
+			}
+			out[i] = a
+			continue
+		}
+		index[a.Key] = len(out)
+		out = append(out, a)
+	}
+	return out
+}
+
+// DuplicatePolicy controls how a [Handler] resolves context values whose key
+// is also used by an attribute of the record or the logger.
+type DuplicatePolicy int
+
+const (
+	// DuplicatesKeep emits both the context value and the attribute.
+	DuplicatesKeep DuplicatePolicy = iota
+	// DuplicatesRecordWins drops the context value.
+	DuplicatesRecordWins
+	// DuplicatesContextWins drops the attribute.
+	DuplicatesContextWins
+	// DuplicatesSuffix emits both, adding [HandlerOptions.DuplicateSuffix]
+	// to the key of the context value.
+	DuplicatesSuffix
+)
+
+// defaultDuplicateSuffix is used by [DuplicatesSuffix] if no suffix is configured.
+const defaultDuplicateSuffix = "_ctx"
+
+// HandlerOptions are options for a [Handler].
+// A zero HandlerOptions consists entirely of default values.
+type HandlerOptions struct {
+	// ContextFirst emits context values before the record's attributes.
+	// By default, context values are emitted after them.
+	ContextFirst bool
+
+	// RootContext emits context values at the top level of the record,
+	// even if the handler has groups applied via WithGroup.
+	// By default, context values are added to the current group.
+	RootContext bool
+
+	// ContextGroup, if set, nests context values in a group with this name.
+	ContextGroup string
+
+	// Duplicates is the policy used when a context value has the same key as
+	// an attribute of the record, or one added with WithAttrs, in the same group.
+	// By default, both are emitted.
+	Duplicates DuplicatePolicy
+
+	// DuplicateSuffix is added to the key of duplicate context values when
+	// using [DuplicatesSuffix]. Defaults to "_ctx".
+	DuplicateSuffix string
+
+	// OnDuplicate, if set, is called with the key of each duplicate context value,
+	// regardless of the policy.
+	OnDuplicate func(ctx context.Context, key string)
+}
+
 // Handler is a slog.Handler that adds context values to the log record.
-// Values are added via [WithValues].
+// Values are added via [WithValues] and registered [Extractor] functions.
 type Handler struct {
-	h slog.Handler
+	h    slog.Handler
+	opts HandlerOptions
+
+	// base is the inner handler given to NewHandler,
+	// and scope records the groups and attrs applied to it since.
+	base  slog.Handler
+	scope []scope
+}
+
+// scope is either a group or a list of attrs applied to a handler.
+type scope struct {
+	group string
+	attrs []slog.Attr
 }
 
 // NewHandler configures a new context aware slog handler.
 // If h is nil, the default slog handler is used.
 func NewHandler(h slog.Handler) Handler {
-	return Handler{h}
+	return NewHandlerWithOptions(h, nil)
+}
+
+// NewHandlerWithOptions configures a new context aware slog handler using the given options.
+// If h is nil, the default slog handler is used.
+// If opts is nil, the default options are used.
+func NewHandlerWithOptions(h slog.Handler, opts *HandlerOptions) Handler {
+	if opts == nil {
+		opts = &HandlerOptions{}
+	}
+	return Handler{h: h, opts: *opts, base: h}
 }
 
 func (h Handler) inner() slog.Handler {
@@ -71,21 +235,182 @@ func (h Handler) inner() slog.Handler {
 }
 
 func (h Handler) Enabled(ctx context.Context, level slog.Level) bool {
-	return h.inner().Enabled(ctx, level)
+	return buffering(ctx, level) || enabled(ctx, h.inner(), level)
 }
 
 func (h Handler) Handle(ctx context.Context, r slog.Record) error {
-	values := get(ctx)
-	for k, v := range values {
-		r.Add(k, v)
+	target, r := h.build(ctx, r)
+	if b := bufferFromContext(ctx); b != nil {
+		// Ask the target whether it would have written the record without the
+		// buffer, in case it is a Handler too.
+		enabled := enabled(context.WithValue(ctx, bufferKey{}, (*Buffer)(nil)), target, r.Level)
+		if b.add(ctx, target, r, enabled) {
+			return nil
+		}
+	}
+	return target.Handle(ctx, r)
+}
+
+// build returns r with the context values added, and the handler to write it to.
+func (h Handler) build(ctx context.Context, r slog.Record) (slog.Handler, slog.Record) {
+	values := contextAttrs(ctx)
+	if len(values) == 0 {
+		return h.inner(), r
+	}
+	if h.opts.ContextGroup != "" {
+		values = []slog.Attr{{Key: h.opts.ContextGroup, Value: slog.GroupValue(values...)}}
+	}
+
+	grouped := slices.ContainsFunc(h.scope, func(s scope) bool { return s.group != "" })
+	keys := h.scopeKeys()
+	if (h.opts.RootContext && grouped) ||
+		(h.opts.Duplicates == DuplicatesContextWins && slices.ContainsFunc(values, func(a slog.Attr) bool { return slices.Contains(keys, a.Key) })) {
+		// The inner handler has already applied groups or attrs that need to
+		// change, so rebuild them as attrs and use the base handler instead.
+		nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		nr.AddAttrs(h.nest(r, func(attrs []slog.Attr) []slog.Attr {
+			return h.merge(ctx, attrs, values, nil)
+		})...)
+		base := h.base
+		if base == nil {
+			base = slog.Default().Handler()
+		}
+		return base, nr
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(h.merge(ctx, recordAttrs(r), values, keys)...)
+	return h.inner(), nr
+}
+
+// merge adds the context values to attrs, resolving duplicate keys according
+// to the handler's policy. keys are the keys of any other attrs in the same group.
+func (h Handler) merge(ctx context.Context, attrs, values []slog.Attr, keys []string) []slog.Attr {
+	if h.opts.Duplicates != DuplicatesKeep || h.opts.OnDuplicate != nil {
+		used := make(map[string]bool, len(attrs)+len(keys))
+		for _, a := range attrs {
+			used[a.Key] = true
+		}
+		for _, k := range keys {
+			used[k] = true
+		}
+
+		drop := map[string]bool{}
+		merged := make([]slog.Attr, 0, len(values))
+		for _, v := range values {
+			if v.Key == badKey || !used[v.Key] {
+				merged = append(merged, v)
+				continue
+			}
+			if h.opts.OnDuplicate != nil {
+				h.opts.OnDuplicate(ctx, v.Key)
+			}
+			switch h.opts.Duplicates {
+			case DuplicatesRecordWins:
+				continue
+			case DuplicatesContextWins:
+				drop[v.Key] = true
+			case DuplicatesSuffix:
+				suffix := h.opts.DuplicateSuffix
+				if suffix == "" {
+					suffix = defaultDuplicateSuffix
+				}
+				v.Key += suffix
+			}
+			merged = append(merged, v)
+		}
+		values = merged
+		if len(drop) > 0 {
+			attrs = slices.DeleteFunc(slices.Clone(attrs), func(a slog.Attr) bool { return drop[a.Key] })
+		}
+	}
+
+	if h.opts.ContextFirst {
+		return append(slices.Clip(values), attrs...)
 	}
-	return h.inner().Handle(ctx, r)
+	return append(slices.Clip(attrs), values...)
+}
+
+// scopeKeys returns the keys of the attrs applied to the handler in the
+// group where context values are added.
+func (h Handler) scopeKeys() []string {
+	var keys []string
+	for i := len(h.scope) - 1; i >= 0; i-- {
+		s := h.scope[i]
+		if s.group != "" {
+			break
+		}
+		for _, a := range s.attrs {
+			keys = append(keys, a.Key)
+		}
+	}
+	return keys
+}
+
+// nest returns the attrs of r nested in the handler's scope,
+// as they would be emitted by the inner handler.
+// f is applied to the attrs of the group where context values are added.
+func (h Handler) nest(r slog.Record, f func([]slog.Attr) []slog.Attr) []slog.Attr {
+	attrs := recordAttrs(r)
+	innermost := true
+	for i := len(h.scope) - 1; i >= 0; i-- {
+		s := h.scope[i]
+		if s.group == "" {
+			attrs = append(slices.Clip(s.attrs), attrs...)
+			continue
+		}
+		if innermost && !h.opts.RootContext {
+			attrs = f(attrs)
+		}
+		innermost = false
+		attrs = []slog.Attr{{Key: s.group, Value: slog.GroupValue(attrs...)}}
+	}
+	if innermost || h.opts.RootContext {
+		attrs = f(attrs)
+	}
+	return attrs
+}
+
+// scopeRecord returns r with the attrs of scope added, nested in its groups.
+func scopeRecord(scope []scope, r slog.Record) slog.Record {
+	if len(scope) == 0 {
+		return r
+	}
+	attrs := recordAttrs(r)
+	for i := len(scope) - 1; i >= 0; i-- {
+		s := scope[i]
+		if s.group == "" {
+			attrs = append(slices.Clip(s.attrs), attrs...)
+			continue
+		}
+		attrs = []slog.Attr{{Key: s.group, Value: slog.GroupValue(attrs...)}}
+	}
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(attrs...)
+	return nr
+}
+
+// recordAttrs returns the attrs of r.
+func recordAttrs(r slog.Record) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
 }
 
 func (h Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return Handler{h.inner().WithAttrs(attrs)}
+	h.scope = append(slices.Clip(h.scope), scope{attrs: attrs})
+	h.h = h.inner().WithAttrs(attrs)
+	return h
 }
 
 func (h Handler) WithGroup(name string) slog.Handler {
-	return Handler{h.inner().WithGroup(name)}
+	if name == "" {
+		return h
+	}
+	h.scope = append(slices.Clip(h.scope), scope{group: name})
+	h.h = h.inner().WithGroup(name)
+	return h
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"reflect"
 	"testing"
@@ -51,3 +52,168 @@ func TestContextHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestContextHandlerOrder(t *testing.T) {
+	ctx := context.Background()
+	ctx = WithValues(ctx, "a", 1, "b", 2)
+	ctx = WithValues(ctx, "c", 3)
+	ctx = WithValues(ctx, "a", 4, "d", 5)
+
+	for _, tc := range []struct {
+		name string
+		opts *HandlerOptions
+		want string
+	}{
+		{"default", nil, "level=INFO msg=hello rec=true a=4 b=2 c=3 d=5\n"},
+		{"context first", &HandlerOptions{ContextFirst: true}, "level=INFO msg=hello a=4 b=2 c=3 d=5 rec=true\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Run a few times to catch any nondeterminism.
+			for range 10 {
+				b := new(bytes.Buffer)
+				log := slog.New(NewHandlerWithOptions(slog.NewTextHandler(b, testopts), tc.opts))
+				log.InfoContext(ctx, "hello", "rec", true)
+				if got := b.String(); got != tc.want {
+					t.Fatalf("want %q, got %q", tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWithValuesArgs(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		ctx  func(context.Context) context.Context
+		want string
+	}{
+		{
+			name: "attrs",
+			ctx: func(ctx context.Context) context.Context {
+				return WithValues(ctx, slog.Int("a", 1), "b", 2)
+			},
+			want: "level=INFO msg=hello a=1 b=2\n",
+		},
+		{
+			name: "odd arguments",
+			ctx: func(ctx context.Context) context.Context {
+				return WithValues(ctx, "a", 1, "b")
+			},
+			want: "level=INFO msg=hello a=1 !BADKEY=b\n",
+		},
+		{
+			name: "non-string key",
+			ctx: func(ctx context.Context) context.Context {
+				return WithValues(ctx, 1, "a")
+			},
+			want: "level=INFO msg=hello !BADKEY=1 !BADKEY=a\n",
+		},
+		{
+			name: "WithAttrs",
+			ctx: func(ctx context.Context) context.Context {
+				return WithAttrs(ctx, slog.String("a", "b"))
+			},
+			want: "level=INFO msg=hello a=b\n",
+		},
+		{
+			name: "merged groups",
+			ctx: func(ctx context.Context) context.Context {
+				ctx = WithValues(ctx, slog.Group("request", "id", 1, "path", "/"))
+				ctx = WithValues(ctx, "a", "b")
+				return WithValues(ctx, slog.Group("request", "user", "me", "id", 2))
+			},
+			want: "level=INFO msg=hello request.id=2 request.path=/ request.user=me a=b\n",
+		},
+		{
+			name: "group replaced by value",
+			ctx: func(ctx context.Context) context.Context {
+				ctx = WithValues(ctx, slog.Group("request", "id", 1))
+				return WithValues(ctx, "request", "none")
+			},
+			want: "level=INFO msg=hello request=none\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			log := slog.New(NewHandler(slog.NewTextHandler(b, testopts)))
+			log.InfoContext(tc.ctx(context.Background()), "hello")
+			if got := b.String(); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestContextHandlerRoot(t *testing.T) {
+	ctx := WithValues(context.Background(), "request_id", "abc")
+
+	for _, tc := range []struct {
+		name string
+		opts *HandlerOptions
+		want string
+	}{
+		{"default", nil, "level=INFO msg=hello g.a=1 g.h.b=2 g.h.c=3 g.h.request_id=abc\n"},
+		{"root", &HandlerOptions{RootContext: true}, "level=INFO msg=hello g.a=1 g.h.b=2 g.h.c=3 request_id=abc\n"},
+		{"root first", &HandlerOptions{RootContext: true, ContextFirst: true}, "level=INFO msg=hello request_id=abc g.a=1 g.h.b=2 g.h.c=3\n"},
+		{"root group", &HandlerOptions{RootContext: true, ContextGroup: "ctx"}, "level=INFO msg=hello g.a=1 g.h.b=2 g.h.c=3 ctx.request_id=abc\n"},
+		{"group", &HandlerOptions{ContextGroup: "ctx"}, "level=INFO msg=hello g.a=1 g.h.b=2 g.h.c=3 g.h.ctx.request_id=abc\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			log := slog.New(NewHandlerWithOptions(slog.NewTextHandler(b, testopts), tc.opts))
+			log = log.WithGroup("g").With("a", 1).WithGroup("h").With("b", 2)
+			log.InfoContext(ctx, "hello", "c", 3)
+			if got := b.String(); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestContextHandlerDuplicates(t *testing.T) {
+	ctx := WithValues(context.Background(), "a", "ctx", "b", "ctx", "c", "ctx")
+
+	for _, tc := range []struct {
+		name string
+		opts *HandlerOptions
+		want string
+	}{
+		{"keep", nil, "level=INFO msg=hello a=with b=rec a=ctx b=ctx c=ctx\n"},
+		{"record wins", &HandlerOptions{Duplicates: DuplicatesRecordWins}, "level=INFO msg=hello a=with b=rec c=ctx\n"},
+		{"context wins", &HandlerOptions{Duplicates: DuplicatesContextWins}, "level=INFO msg=hello a=ctx b=ctx c=ctx\n"},
+		{"suffix", &HandlerOptions{Duplicates: DuplicatesSuffix}, "level=INFO msg=hello a=with b=rec a_ctx=ctx b_ctx=ctx c=ctx\n"},
+		{"custom suffix", &HandlerOptions{Duplicates: DuplicatesSuffix, DuplicateSuffix: ".1"}, "level=INFO msg=hello a=with b=rec a.1=ctx b.1=ctx c=ctx\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			log := slog.New(NewHandlerWithOptions(slog.NewTextHandler(b, testopts), tc.opts))
+			log.With("a", "with").InfoContext(ctx, "hello", "b", "rec")
+			if got := b.String(); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("groups", func(t *testing.T) {
+		b := new(bytes.Buffer)
+		log := slog.New(NewHandlerWithOptions(slog.NewTextHandler(b, testopts), &HandlerOptions{
+			Duplicates: DuplicatesContextWins,
+		}))
+		// Only attrs in the same group as the context values are duplicates.
+		log.With("a", "with").WithGroup("g").With("b", "with").InfoContext(ctx, "hello", "c", "rec")
+		if want, got := "level=INFO msg=hello a=with g.a=ctx g.b=ctx g.c=ctx\n", b.String(); got != want {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	})
+
+	t.Run("callback", func(t *testing.T) {
+		var got []string
+		log := slog.New(NewHandlerWithOptions(slog.NewTextHandler(io.Discard, nil), &HandlerOptions{
+			OnDuplicate: func(_ context.Context, key string) { got = append(got, key) },
+		}))
+		log.With("a", "with").InfoContext(ctx, "hello", "b", "rec")
+		if want := []string{"a", "b"}; !reflect.DeepEqual(want, got) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	})
+}
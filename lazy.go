@@ -0,0 +1,62 @@
+package clog
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// lazy is a [slog.LogValuer] that computes its value at most once.
+type lazy struct {
+	once sync.Once
+	f    func() any
+	v    any
+}
+
+// Lazy returns a value that calls f to compute the value to log only when a
+// record containing it is written. f is called at most once, even if the value
+// is logged several times, e.g. when stored with [WithValues].
+//
+// The returned value also implements [fmt.Formatter], so it can be passed to
+// Infof and friends.
+func Lazy(f func() any) slog.LogValuer {
+	return &lazy{f: f}
+}
+
+// LazyAttr returns an attribute whose value is computed by f only when needed.
+// See [Lazy].
+func LazyAttr(key string, f func() any) slog.Attr {
+	return slog.Any(key, Lazy(f))
+}
+
+func (l *lazy) value() any {
+	l.once.Do(func() { l.v = l.f() })
+	return l.v
+}
+
+// LogValue implements [slog.LogValuer].
+func (l *lazy) LogValue() slog.Value {
+	return slog.AnyValue(l.value())
+}
+
+// Format implements [fmt.Formatter].
+func (l *lazy) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), l.value())
+}
+
+// resolve returns attrs with any [slog.LogValuer] values resolved.
+// attrs is not modified.
+func resolve(attrs []slog.Attr) []slog.Attr {
+	for i, a := range attrs {
+		if a.Value.Kind() != slog.KindLogValuer {
+			continue
+		}
+		out := slices.Clone(attrs)
+		for j := i; j < len(out); j++ {
+			out[j].Value = out[j].Value.Resolve()
+		}
+		return out
+	}
+	return attrs
+}
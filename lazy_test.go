@@ -0,0 +1,60 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := New(slog.NewTextHandler(b, testopts))
+
+	calls := 0
+	value := func() any {
+		calls++
+		return "expensive"
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		b.Reset()
+		calls = 0
+		ctx := WithValues(context.Background(), "ctx", Lazy(value))
+		log.DebugContext(ctx, "hello", LazyAttr("rec", value))
+		log.Debugf("hello %s", Lazy(value))
+		if calls != 0 {
+			t.Errorf("want 0 calls, got %d", calls)
+		}
+		if b.Len() != 0 {
+			t.Errorf("want empty, got %q", b.String())
+		}
+	})
+
+	t.Run("context", func(t *testing.T) {
+		b.Reset()
+		calls = 0
+		ctx := WithValues(context.Background(), "ctx", Lazy(value))
+		log.InfoContext(ctx, "hello")
+		log.InfoContext(ctx, "again")
+		if want := "level=INFO msg=hello ctx=expensive\nlevel=INFO msg=again ctx=expensive\n"; b.String() != want {
+			t.Errorf("want %q, got %q", want, b.String())
+		}
+		if calls != 1 {
+			t.Errorf("want 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("record", func(t *testing.T) {
+		b.Reset()
+		calls = 0
+		log.Info("hello", LazyAttr("rec", value))
+		log.Infof("hello %s", Lazy(value))
+		if want := "level=INFO msg=hello rec=expensive\nlevel=INFO msg=\"hello expensive\"\n"; b.String() != want {
+			t.Errorf("want %q, got %q", want, b.String())
+		}
+		if calls != 2 {
+			t.Errorf("want 2 calls, got %d", calls)
+		}
+	})
+}
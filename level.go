@@ -0,0 +1,124 @@
+package clog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Levels supported by clog, in addition to the [slog] levels.
+// These line up with the severities supported by Google Cloud Logging,
+// except for LevelFatal, which is used by Fatal and friends.
+const (
+	LevelTrace     = slog.Level(-8)
+	LevelDebug     = slog.LevelDebug
+	LevelInfo      = slog.LevelInfo
+	LevelNotice    = slog.Level(2)
+	LevelWarn      = slog.LevelWarn
+	LevelError     = slog.LevelError
+	LevelCritical  = slog.Level(12)
+	LevelFatal     = slog.Level(14)
+	LevelAlert     = slog.Level(16)
+	LevelEmergency = slog.Level(20)
+)
+
+// levelNames are the names of the named levels, in increasing order.
+var levelNames = []struct {
+	level slog.Level
+	name  string
+}{
+	{LevelTrace, "TRACE"},
+	{LevelDebug, "DEBUG"},
+	{LevelInfo, "INFO"},
+	{LevelNotice, "NOTICE"},
+	{LevelWarn, "WARN"},
+	{LevelError, "ERROR"},
+	{LevelCritical, "CRITICAL"},
+	{LevelFatal, "FATAL"},
+	{LevelAlert, "ALERT"},
+	{LevelEmergency, "EMERGENCY"},
+}
+
+// LevelString returns a name for the level, like [slog.Level.String], but
+// using the names of clog's levels.
+// Levels between named levels are shown as an offset, e.g. "NOTICE+1".
+func LevelString(l slog.Level) string {
+	i := len(levelNames) - 1
+	for i > 0 && l < levelNames[i].level {
+		i--
+	}
+	name, offset := levelNames[i].name, l-levelNames[i].level
+	if offset == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s%+d", name, offset)
+}
+
+// ParseLevel parses a level name, as returned by [LevelString].
+// Names are case-insensitive and may be followed by an offset, e.g. "info+2".
+// "WARNING" is accepted as an alias for "WARN".
+func ParseLevel(s string) (slog.Level, error) {
+	name, offset := s, 0
+	if i := strings.IndexAny(s, "+-"); i >= 0 {
+		var err error
+		name = s[:i]
+		offset, err = strconv.Atoi(s[i:])
+		if err != nil {
+			return 0, fmt.Errorf("clog: level string %q: %w", s, err)
+		}
+	}
+	name = strings.ToUpper(name)
+	if name == "WARNING" {
+		name = "WARN"
+	}
+	for _, n := range levelNames {
+		if n.name == name {
+			return n.level + slog.Level(offset), nil
+		}
+	}
+	return 0, fmt.Errorf("clog: unknown level name %q", s)
+}
+
+// ReplaceLevelName is a [slog.HandlerOptions] ReplaceAttr function that
+// formats the top-level level attribute using [LevelString].
+func ReplaceLevelName(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if l, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(LevelString(l))
+		}
+	}
+	return a
+}
+
+type levelKey struct{}
+
+// WithLevel returns a new context that overrides the minimum level of
+// [Handler] and the package-level logging functions (e.g. [DebugContext]).
+// This can both lower the level, e.g. to debug a single request, or raise it.
+// Without an override, the inner handler's level is used.
+func WithLevel(ctx context.Context, level slog.Leveler) context.Context {
+	return context.WithValue(ctx, levelKey{}, level)
+}
+
+// levelFromContext returns the minimum level set with [WithLevel], if any.
+func levelFromContext(ctx context.Context) (slog.Level, bool) {
+	if l, ok := ctx.Value(levelKey{}).(slog.Leveler); ok && l != nil {
+		return l.Level(), true
+	}
+	return 0, false
+}
+
+// enabled reports whether h handles records at the given level,
+// taking any level set with [WithLevel] into account, and any [Buffer] if h
+// is a [Handler].
+func enabled(ctx context.Context, h slog.Handler, level slog.Level) bool {
+	if _, ok := h.(Handler); ok && buffering(ctx, level) {
+		return true
+	}
+	if min, ok := levelFromContext(ctx); ok {
+		return level >= min
+	}
+	return h.Enabled(ctx, level)
+}
@@ -0,0 +1,129 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestWithLevel(t *testing.T) {
+	b := new(bytes.Buffer)
+	// The inner handler only logs at info and above.
+	log := New(slog.NewTextHandler(b, testopts))
+	ctx := WithLogger(context.Background(), log)
+
+	for _, tc := range []struct {
+		name string
+		ctx  context.Context
+		fn   func(context.Context)
+		want string
+	}{
+		{"no override", ctx, func(ctx context.Context) { DebugContext(ctx, "hello") }, ""},
+		{"lowered", WithLevel(ctx, slog.LevelDebug), func(ctx context.Context) { DebugContext(ctx, "hello") }, "level=DEBUG msg=hello\n"},
+		{"lowered f", WithLevel(ctx, slog.LevelDebug), func(ctx context.Context) { DebugContextf(ctx, "hello %d", 1) }, "level=DEBUG msg=\"hello 1\"\n"},
+		{"lowered slog", WithLevel(ctx, slog.LevelDebug), func(ctx context.Context) { log.DebugContext(ctx, "hello") }, "level=DEBUG msg=hello\n"},
+		{"raised", WithLevel(ctx, slog.LevelError), func(ctx context.Context) { WarnContext(ctx, "hello") }, ""},
+		{"raised error", WithLevel(ctx, slog.LevelError), func(ctx context.Context) { ErrorContext(ctx, "hello") }, "level=ERROR msg=hello\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b.Reset()
+			tc.fn(tc.ctx)
+			if got := b.String(); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLevelString(t *testing.T) {
+	for _, tc := range []struct {
+		level slog.Level
+		want  string
+	}{
+		{LevelTrace - 2, "TRACE-2"},
+		{LevelTrace, "TRACE"},
+		{LevelDebug, "DEBUG"},
+		{LevelInfo, "INFO"},
+		{LevelInfo + 1, "INFO+1"},
+		{LevelNotice, "NOTICE"},
+		{LevelNotice + 1, "NOTICE+1"},
+		{LevelWarn, "WARN"},
+		{LevelError, "ERROR"},
+		{LevelCritical, "CRITICAL"},
+		{LevelCritical + 1, "CRITICAL+1"},
+		{LevelFatal, "FATAL"},
+		{LevelAlert, "ALERT"},
+		{LevelEmergency, "EMERGENCY"},
+		{LevelEmergency + 10, "EMERGENCY+10"},
+	} {
+		t.Run(tc.want, func(t *testing.T) {
+			if got := LevelString(tc.level); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+			got, err := ParseLevel(tc.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.level {
+				t.Errorf("want %v, got %v", tc.level, got)
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want slog.Level
+	}{
+		{"info+2", LevelInfo + 2},
+		{"Notice", LevelNotice},
+		{"warning", LevelWarn},
+		{"critical-1", LevelCritical - 1},
+		{"trace", LevelTrace},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseLevel(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "bogus", "info+", "info+x"} {
+		if _, err := ParseLevel(in); err == nil {
+			t.Errorf("ParseLevel(%q): want error", in)
+		}
+	}
+}
+
+func TestLevelFunctions(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := New(slog.NewTextHandler(b, &slog.HandlerOptions{
+		Level: LevelTrace,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			return ReplaceLevelName(groups, testopts.ReplaceAttr(groups, a))
+		},
+	}))
+	ctx := WithLogger(context.Background(), log)
+
+	log.Trace("a")
+	log.Noticef("b %d", 1)
+	log.CriticalContext(ctx, "c")
+	AlertContext(ctx, "d")
+	EmergencyContextf(ctx, "e %d", 2)
+
+	want := `level=TRACE msg=a
+level=NOTICE msg="b 1"
+level=CRITICAL msg=c
+level=ALERT msg=d
+level=EMERGENCY msg="e 2"
+`
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
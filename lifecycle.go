@@ -0,0 +1,122 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Flusher is implemented by handlers that buffer output.
+// Flush writes any buffered records, returning when they have been written
+// or the context is done.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Closer is implemented by handlers that hold resources, such as background
+// goroutines or network connections.
+// Close flushes any buffered records and releases the resources.
+// The handler must not be used after Close.
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// Flush flushes h if it implements [Flusher].
+func Flush(ctx context.Context, h slog.Handler) error {
+	if f, ok := h.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Close closes h if it implements [Closer], or flushes it if it only
+// implements [Flusher].
+func Close(ctx context.Context, h slog.Handler) error {
+	if c, ok := h.(Closer); ok {
+		return c.Close(ctx)
+	}
+	return Flush(ctx, h)
+}
+
+// Flush implements [Flusher] by flushing the inner handler.
+func (h Handler) Flush(ctx context.Context) error {
+	return Flush(ctx, h.inner())
+}
+
+// Close implements [Closer] by closing the inner handler.
+func (h Handler) Close(ctx context.Context) error {
+	return Close(ctx, h.inner())
+}
+
+// Flush flushes the logger's handler. See [Flusher].
+func (l *Logger) Flush(ctx context.Context) error {
+	return Flush(ctx, l.Handler())
+}
+
+// Close closes the logger's handler. See [Closer].
+func (l *Logger) Close(ctx context.Context) error {
+	return Close(ctx, l.Handler())
+}
+
+// Shutdown closes the handler of the default [slog.Logger], flushing any
+// buffered records. It is intended to be called before a program exits.
+//
+//	defer clog.Shutdown(context.Background())
+func Shutdown(ctx context.Context) error {
+	return Close(ctx, slog.Default().Handler())
+}
+
+// FlushOnSignal flushes the handler of the default [slog.Logger] when the
+// program receives one of the given signals (SIGTERM if none are given).
+// After flushing, the signal is raised again, so the program still handles it
+// as it otherwise would, e.g. by terminating.
+// The returned function stops listening for the signals.
+//
+// The handler is flushed when the signal arrives, not when the program exits,
+// so records logged during a graceful shutdown afterwards may still be
+// buffered; call [Shutdown] before exiting to write them.
+//
+// If the program also handles the signals with [signal.Notify], it receives
+// each signal twice: once when it arrives, and again when it is raised after
+// flushing. Programs that treat a second signal as a request to exit
+// immediately should call [Flush] from their own signal handling instead.
+func FlushOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM}
+	}
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, signals...)
+	go func() {
+		select {
+		case sig := <-c:
+			ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			_ = Flush(ctx, slog.Default().Handler())
+			cancel()
+			signal.Stop(c)
+			if err := raise(sig); err != nil {
+				os.Exit(1)
+			}
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
+
+// raise sends sig to the current process.
+func raise(sig os.Signal) error {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		return err
+	}
+	return p.Signal(sig)
+}
@@ -0,0 +1,63 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+// lifecycleHandler is a handler that records calls to Flush and Close.
+type lifecycleHandler struct {
+	slog.Handler
+	calls *[]string
+}
+
+func (h lifecycleHandler) Flush(context.Context) error {
+	*h.calls = append(*h.calls, "flush")
+	return nil
+}
+
+func (h lifecycleHandler) Close(context.Context) error {
+	*h.calls = append(*h.calls, "close")
+	return nil
+}
+
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+	var calls []string
+	inner := lifecycleHandler{slog.DiscardHandler, &calls}
+
+	h := NewHandler(inner)
+	if err := h.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := Close(ctx, h); err != nil {
+		t.Fatal(err)
+	}
+
+	log := New(inner)
+	if err := log.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	old := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(old) })
+	slog.SetDefault(slog.New(h))
+	if err := Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"flush", "close", "flush", "close", "close"}
+	if !slices.Equal(want, calls) {
+		t.Errorf("want %v, got %v", want, calls)
+	}
+
+	// Handlers that don't implement the interfaces are fine too.
+	if err := Close(ctx, NewHandler(slog.DiscardHandler)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,7 +3,6 @@ package clog
 import (
 	"context"
 	"log/slog"
-	"os"
 )
 
 // Info calls Info on the default logger.
@@ -91,26 +90,166 @@ func DebugContextf(ctx context.Context, format string, args ...any) {
 	wrapf(ctx, FromContext(ctx), slog.LevelDebug, format, args...)
 }
 
-// Fatal calls Error on the default logger, then exits.
+// Trace calls Trace on the default logger.
+func Trace(msg string, args ...any) {
+	wrap(context.Background(), DefaultLogger(), LevelTrace, msg, args...)
+}
+
+// TraceContext calls TraceContext on the context logger.
+// If a Logger is found in the context, it will be used.
+func TraceContext(ctx context.Context, msg string, args ...any) {
+	wrap(ctx, FromContext(ctx), LevelTrace, msg, args...)
+}
+
+// Tracef calls Tracef on the default logger.
+func Tracef(format string, args ...any) {
+	wrapf(context.Background(), DefaultLogger(), LevelTrace, format, args...)
+}
+
+// TraceContextf calls TraceContextf on the context logger.
+// If a Logger is found in the context, it will be used.
+func TraceContextf(ctx context.Context, format string, args ...any) {
+	wrapf(ctx, FromContext(ctx), LevelTrace, format, args...)
+}
+
+// Notice calls Notice on the default logger.
+func Notice(msg string, args ...any) {
+	wrap(context.Background(), DefaultLogger(), LevelNotice, msg, args...)
+}
+
+// NoticeContext calls NoticeContext on the context logger.
+// If a Logger is found in the context, it will be used.
+func NoticeContext(ctx context.Context, msg string, args ...any) {
+	wrap(ctx, FromContext(ctx), LevelNotice, msg, args...)
+}
+
+// Noticef calls Noticef on the default logger.
+func Noticef(format string, args ...any) {
+	wrapf(context.Background(), DefaultLogger(), LevelNotice, format, args...)
+}
+
+// NoticeContextf calls NoticeContextf on the context logger.
+// If a Logger is found in the context, it will be used.
+func NoticeContextf(ctx context.Context, format string, args ...any) {
+	wrapf(ctx, FromContext(ctx), LevelNotice, format, args...)
+}
+
+// Critical calls Critical on the default logger.
+func Critical(msg string, args ...any) {
+	wrap(context.Background(), DefaultLogger(), LevelCritical, msg, args...)
+}
+
+// CriticalContext calls CriticalContext on the context logger.
+// If a Logger is found in the context, it will be used.
+func CriticalContext(ctx context.Context, msg string, args ...any) {
+	wrap(ctx, FromContext(ctx), LevelCritical, msg, args...)
+}
+
+// Criticalf calls Criticalf on the default logger.
+func Criticalf(format string, args ...any) {
+	wrapf(context.Background(), DefaultLogger(), LevelCritical, format, args...)
+}
+
+// CriticalContextf calls CriticalContextf on the context logger.
+// If a Logger is found in the context, it will be used.
+func CriticalContextf(ctx context.Context, format string, args ...any) {
+	wrapf(ctx, FromContext(ctx), LevelCritical, format, args...)
+}
+
+// Alert calls Alert on the default logger.
+func Alert(msg string, args ...any) {
+	wrap(context.Background(), DefaultLogger(), LevelAlert, msg, args...)
+}
+
+// AlertContext calls AlertContext on the context logger.
+// If a Logger is found in the context, it will be used.
+func AlertContext(ctx context.Context, msg string, args ...any) {
+	wrap(ctx, FromContext(ctx), LevelAlert, msg, args...)
+}
+
+// Alertf calls Alertf on the default logger.
+func Alertf(format string, args ...any) {
+	wrapf(context.Background(), DefaultLogger(), LevelAlert, format, args...)
+}
+
+// AlertContextf calls AlertContextf on the context logger.
+// If a Logger is found in the context, it will be used.
+func AlertContextf(ctx context.Context, format string, args ...any) {
+	wrapf(ctx, FromContext(ctx), LevelAlert, format, args...)
+}
+
+// Emergency calls Emergency on the default logger.
+func Emergency(msg string, args ...any) {
+	wrap(context.Background(), DefaultLogger(), LevelEmergency, msg, args...)
+}
+
+// EmergencyContext calls EmergencyContext on the context logger.
+// If a Logger is found in the context, it will be used.
+func EmergencyContext(ctx context.Context, msg string, args ...any) {
+	wrap(ctx, FromContext(ctx), LevelEmergency, msg, args...)
+}
+
+// Emergencyf calls Emergencyf on the default logger.
+func Emergencyf(format string, args ...any) {
+	wrapf(context.Background(), DefaultLogger(), LevelEmergency, format, args...)
+}
+
+// EmergencyContextf calls EmergencyContextf on the context logger.
+// If a Logger is found in the context, it will be used.
+func EmergencyContextf(ctx context.Context, format string, args ...any) {
+	wrapf(ctx, FromContext(ctx), LevelEmergency, format, args...)
+}
+
+// Fatal calls Fatal on the default logger.
 func Fatal(msg string, args ...any) {
-	wrap(context.Background(), DefaultLogger(), slog.LevelError, msg, args...)
-	os.Exit(1)
+	logger := DefaultLogger()
+	wrap(context.Background(), logger, LevelFatal, msg, args...)
+	logger.exit(context.Background())
 }
 
-// FatalContext calls ErrorContext on the context logger, then exits.
+// FatalContext calls FatalContext on the context logger.
 func FatalContext(ctx context.Context, msg string, args ...any) {
-	wrap(ctx, FromContext(ctx), slog.LevelError, msg, args...)
-	os.Exit(1)
+	logger := FromContext(ctx)
+	wrap(ctx, logger, LevelFatal, msg, args...)
+	logger.exit(ctx)
 }
 
-// Fatalf calls Errorf on the default logger, then exits.
+// Fatalf calls Fatalf on the default logger.
 func Fatalf(format string, args ...any) {
-	wrapf(context.Background(), DefaultLogger(), slog.LevelError, format, args...)
-	os.Exit(1)
+	logger := DefaultLogger()
+	wrapf(context.Background(), logger, LevelFatal, format, args...)
+	logger.exit(context.Background())
 }
 
-// FatalContextf calls ErrorContextf on the context logger, then exits.
+// FatalContextf calls FatalContextf on the context logger.
 func FatalContextf(ctx context.Context, format string, args ...any) {
-	wrapf(ctx, FromContext(ctx), slog.LevelError, format, args...)
-	os.Exit(1)
+	logger := FromContext(ctx)
+	wrapf(ctx, logger, LevelFatal, format, args...)
+	logger.exit(ctx)
+}
+
+// Panic calls Panic on the default logger.
+func Panic(msg string, args ...any) {
+	wrap(context.Background(), DefaultLogger(), slog.LevelError, msg, args...)
+	panic(msg)
+}
+
+// PanicContext calls PanicContext on the context logger.
+func PanicContext(ctx context.Context, msg string, args ...any) {
+	wrap(ctx, FromContext(ctx), slog.LevelError, msg, args...)
+	panic(msg)
+}
+
+// Panicf calls Panicf on the default logger.
+func Panicf(format string, args ...any) {
+	msg, attrs := sprintf(format, args)
+	wrap(context.Background(), DefaultLogger(), slog.LevelError, msg, attrs...)
+	panic(msg)
+}
+
+// PanicContextf calls PanicContextf on the context logger.
+func PanicContextf(ctx context.Context, format string, args ...any) {
+	msg, attrs := sprintf(format, args)
+	wrap(ctx, FromContext(ctx), slog.LevelError, msg, attrs...)
+	panic(msg)
 }
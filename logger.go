@@ -4,15 +4,26 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
-	"runtime"
 	"time"
 )
 
 // Logger implements a wrapper around [slog.Logger] that adds formatter functions (e.g. Infof, Errorf)
+//
+// Any trailing [slog.Attr] arguments to the formatter functions are added to
+// the record as attributes instead of being formatted, e.g.
+//
+//	log.Infof("fetched %s", url, slog.Int("status", 200))
 type Logger struct {
 	ctx context.Context
 	slog.Logger
+
+	// skip is the number of additional stack frames to skip when recording
+	// the source location.
+	skip int
+
+	// exitFunc and exitCode override the global settings used by Fatal.
+	exitFunc ExitFunc
+	exitCode *int
 }
 
 // DefaultLogger returns a new logger that uses the default [slog.Logger].
@@ -53,12 +64,25 @@ func With(args ...any) *Logger {
 
 // With calls [Logger.With] on the logger.
 func (l *Logger) With(args ...any) *Logger {
-	return NewLoggerWithContext(l.context(), l.Logger.With(args...))
+	return l.with(l.context(), l.Logger.With(args...))
 }
 
 // WithGroup calls [Logger.WithGroup] on the default logger.
 func (l *Logger) WithGroup(name string) *Logger {
-	return NewLoggerWithContext(l.context(), l.Logger.WithGroup(name))
+	return l.with(l.context(), l.Logger.WithGroup(name))
+}
+
+// WithContext returns a copy of the logger bound to the given context.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	return l.with(ctx, &l.Logger)
+}
+
+// with returns a copy of the logger with the given context and [slog.Logger].
+func (l *Logger) with(ctx context.Context, sl *slog.Logger) *Logger {
+	c := *l
+	c.ctx = ctx
+	c.Logger = *sl
+	return &c
 }
 
 func (l *Logger) context() context.Context {
@@ -68,6 +92,59 @@ func (l *Logger) context() context.Context {
 	return l.ctx
 }
 
+// merge returns a context that combines the logger's bound context with ctx.
+// Values in ctx take precedence over values in the bound context, including
+// values set with [WithValues].
+func (l *Logger) merge(ctx context.Context) context.Context {
+	bound := l.context()
+	if ctx == nil {
+		return bound
+	}
+	if bound == ctx || bound == context.Background() {
+		return ctx
+	}
+	return &mergedContext{
+		Context: ctx,
+		bound:   bound,
+		values:  mergeValues(get(bound), get(ctx)),
+	}
+}
+
+// mergedContext is a context that looks up values in its embedded context,
+// falling back to the bound context.
+type mergedContext struct {
+	context.Context
+	bound  context.Context
+	values *ctxVal
+}
+
+func (c *mergedContext) Value(key any) any {
+	if key == ctxKey {
+		return c.values
+	}
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.bound.Value(key)
+}
+
+// mergeValues returns the values of bound followed by the values of v.
+func mergeValues(bound, v *ctxVal) *ctxVal {
+	if bound == nil {
+		return v
+	}
+	if v == nil {
+		return bound
+	}
+	for n := v; n != nil; n = n.parent {
+		if n == bound {
+			// v was derived from bound, so it already has its values.
+			return v
+		}
+	}
+	return &ctxVal{parent: bound, attrs: v.all()}
+}
+
 // Info logs at LevelInfo with the given message and treats the args as key/value pairs to form log message attributes.
 func (l *Logger) Info(msg string, args ...any) {
 	wrap(l.context(), l, slog.LevelInfo, msg, args...)
@@ -78,9 +155,14 @@ func (l *Logger) Infof(format string, args ...any) {
 	wrapf(l.context(), l, slog.LevelInfo, format, args...)
 }
 
+// InfoContext logs at LevelInfo with the given context and message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) InfoContext(ctx context.Context, msg string, args ...any) {
+	wrap(l.merge(ctx), l, slog.LevelInfo, msg, args...)
+}
+
 // InfoContextf logs at LevelInfo with the given context, format, and arguments.
 func (l *Logger) InfoContextf(ctx context.Context, format string, args ...any) {
-	wrapf(ctx, l, slog.LevelInfo, format, args...)
+	wrapf(l.merge(ctx), l, slog.LevelInfo, format, args...)
 }
 
 // Warn logs at LevelWarn with the given message and treats the args as key/value pairs to form log message attributes.
@@ -93,9 +175,14 @@ func (l *Logger) Warnf(format string, args ...any) {
 	wrapf(l.context(), l, slog.LevelWarn, format, args...)
 }
 
+// WarnContext logs at LevelWarn with the given context and message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) WarnContext(ctx context.Context, msg string, args ...any) {
+	wrap(l.merge(ctx), l, slog.LevelWarn, msg, args...)
+}
+
 // WarnContextf logs at LevelWarn with the given context, format and arguments.
 func (l *Logger) WarnContextf(ctx context.Context, format string, args ...any) {
-	wrapf(ctx, l, slog.LevelWarn, format, args...)
+	wrapf(l.merge(ctx), l, slog.LevelWarn, format, args...)
 }
 
 // Error logs at LevelError with the given message and treats the args as key/value pairs to form log message attributes.
@@ -108,9 +195,14 @@ func (l *Logger) Errorf(format string, args ...any) {
 	wrapf(l.context(), l, slog.LevelError, format, args...)
 }
 
+// ErrorContext logs at LevelError with the given context and message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	wrap(l.merge(ctx), l, slog.LevelError, msg, args...)
+}
+
 // ErrorContextf logs at LevelError with the given context, format and arguments.
 func (l *Logger) ErrorContextf(ctx context.Context, format string, args ...any) {
-	wrapf(ctx, l, slog.LevelError, format, args...)
+	wrapf(l.merge(ctx), l, slog.LevelError, format, args...)
 }
 
 // Debug logs at LevelDebug with the given message and treats the args as key/value pairs to form log message attributes.
@@ -123,33 +215,178 @@ func (l *Logger) Debugf(format string, args ...any) {
 	wrapf(l.context(), l, slog.LevelDebug, format, args...)
 }
 
+// DebugContext logs at LevelDebug with the given context and message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) DebugContext(ctx context.Context, msg string, args ...any) {
+	wrap(l.merge(ctx), l, slog.LevelDebug, msg, args...)
+}
+
 // DebugContextf logs at LevelDebug with the given context, format and arguments.
 func (l *Logger) DebugContextf(ctx context.Context, format string, args ...any) {
-	wrapf(ctx, l, slog.LevelDebug, format, args...)
+	wrapf(l.merge(ctx), l, slog.LevelDebug, format, args...)
+}
+
+// Trace logs at LevelTrace with the given message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) Trace(msg string, args ...any) {
+	wrap(l.context(), l, LevelTrace, msg, args...)
+}
+
+// Tracef logs at LevelTrace with the given format and arguments.
+func (l *Logger) Tracef(format string, args ...any) {
+	wrapf(l.context(), l, LevelTrace, format, args...)
+}
+
+// TraceContext logs at LevelTrace with the given context and message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) TraceContext(ctx context.Context, msg string, args ...any) {
+	wrap(l.merge(ctx), l, LevelTrace, msg, args...)
+}
+
+// TraceContextf logs at LevelTrace with the given context, format and arguments.
+func (l *Logger) TraceContextf(ctx context.Context, format string, args ...any) {
+	wrapf(l.merge(ctx), l, LevelTrace, format, args...)
+}
+
+// Notice logs at LevelNotice with the given message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) Notice(msg string, args ...any) {
+	wrap(l.context(), l, LevelNotice, msg, args...)
+}
+
+// Noticef logs at LevelNotice with the given format and arguments.
+func (l *Logger) Noticef(format string, args ...any) {
+	wrapf(l.context(), l, LevelNotice, format, args...)
+}
+
+// NoticeContext logs at LevelNotice with the given context and message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) NoticeContext(ctx context.Context, msg string, args ...any) {
+	wrap(l.merge(ctx), l, LevelNotice, msg, args...)
+}
+
+// NoticeContextf logs at LevelNotice with the given context, format and arguments.
+func (l *Logger) NoticeContextf(ctx context.Context, format string, args ...any) {
+	wrapf(l.merge(ctx), l, LevelNotice, format, args...)
+}
+
+// Critical logs at LevelCritical with the given message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) Critical(msg string, args ...any) {
+	wrap(l.context(), l, LevelCritical, msg, args...)
+}
+
+// Criticalf logs at LevelCritical with the given format and arguments.
+func (l *Logger) Criticalf(format string, args ...any) {
+	wrapf(l.context(), l, LevelCritical, format, args...)
+}
+
+// CriticalContext logs at LevelCritical with the given context and message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) CriticalContext(ctx context.Context, msg string, args ...any) {
+	wrap(l.merge(ctx), l, LevelCritical, msg, args...)
+}
+
+// CriticalContextf logs at LevelCritical with the given context, format and arguments.
+func (l *Logger) CriticalContextf(ctx context.Context, format string, args ...any) {
+	wrapf(l.merge(ctx), l, LevelCritical, format, args...)
+}
+
+// Alert logs at LevelAlert with the given message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) Alert(msg string, args ...any) {
+	wrap(l.context(), l, LevelAlert, msg, args...)
+}
+
+// Alertf logs at LevelAlert with the given format and arguments.
+func (l *Logger) Alertf(format string, args ...any) {
+	wrapf(l.context(), l, LevelAlert, format, args...)
+}
+
+// AlertContext logs at LevelAlert with the given context and message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) AlertContext(ctx context.Context, msg string, args ...any) {
+	wrap(l.merge(ctx), l, LevelAlert, msg, args...)
+}
+
+// AlertContextf logs at LevelAlert with the given context, format and arguments.
+func (l *Logger) AlertContextf(ctx context.Context, format string, args ...any) {
+	wrapf(l.merge(ctx), l, LevelAlert, format, args...)
+}
+
+// Emergency logs at LevelEmergency with the given message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) Emergency(msg string, args ...any) {
+	wrap(l.context(), l, LevelEmergency, msg, args...)
+}
+
+// Emergencyf logs at LevelEmergency with the given format and arguments.
+func (l *Logger) Emergencyf(format string, args ...any) {
+	wrapf(l.context(), l, LevelEmergency, format, args...)
+}
+
+// EmergencyContext logs at LevelEmergency with the given context and message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) EmergencyContext(ctx context.Context, msg string, args ...any) {
+	wrap(l.merge(ctx), l, LevelEmergency, msg, args...)
 }
 
-// Fatal logs at LevelError with the given message, then exits.
+// EmergencyContextf logs at LevelEmergency with the given context, format and arguments.
+func (l *Logger) EmergencyContextf(ctx context.Context, format string, args ...any) {
+	wrapf(l.merge(ctx), l, LevelEmergency, format, args...)
+}
+
+// Fatal logs at LevelFatal with the given message, then exits.
 func (l *Logger) Fatal(msg string, args ...any) {
-	wrap(l.context(), l, slog.LevelError, msg, args...)
-	os.Exit(1)
+	wrap(l.context(), l, LevelFatal, msg, args...)
+	l.exit(l.context())
 }
 
-// Fatalf logs at LevelError with the given format and arguments, then exits.
+// Fatalf logs at LevelFatal with the given format and arguments, then exits.
 func (l *Logger) Fatalf(format string, args ...any) {
-	wrapf(l.context(), l, slog.LevelError, format, args...)
-	os.Exit(1)
+	wrapf(l.context(), l, LevelFatal, format, args...)
+	l.exit(l.context())
 }
 
-// FatalContextf logs at LevelError with the given context, format and arguments, then exits.
+// FatalContextf logs at LevelFatal with the given context, format and arguments, then exits.
 func (l *Logger) FatalContextf(ctx context.Context, format string, args ...any) {
-	wrapf(ctx, l, slog.LevelError, format, args...)
-	os.Exit(1)
+	wrapf(l.merge(ctx), l, LevelFatal, format, args...)
+	l.exit(ctx)
 }
 
-// FatalContext logs at LevelError with the given context and message, then exits.
+// FatalContext logs at LevelFatal with the given context and message, then exits.
 func (l *Logger) FatalContext(ctx context.Context, msg string, args ...any) {
-	wrap(ctx, l, slog.LevelError, msg, args...)
-	os.Exit(1)
+	wrap(l.merge(ctx), l, LevelFatal, msg, args...)
+	l.exit(ctx)
+}
+
+// Panic logs at LevelError with the given message, then panics with the message.
+func (l *Logger) Panic(msg string, args ...any) {
+	wrap(l.context(), l, slog.LevelError, msg, args...)
+	panic(msg)
+}
+
+// Panicf logs at LevelError with the given format and arguments, then panics with the formatted message.
+func (l *Logger) Panicf(format string, args ...any) {
+	msg, attrs := sprintf(format, args)
+	wrap(l.context(), l, slog.LevelError, msg, attrs...)
+	panic(msg)
+}
+
+// PanicContext logs at LevelError with the given context and message, then panics with the message.
+func (l *Logger) PanicContext(ctx context.Context, msg string, args ...any) {
+	wrap(l.merge(ctx), l, slog.LevelError, msg, args...)
+	panic(msg)
+}
+
+// PanicContextf logs at LevelError with the given context, format and arguments, then panics with the formatted message.
+func (l *Logger) PanicContextf(ctx context.Context, format string, args ...any) {
+	msg, attrs := sprintf(format, args)
+	wrap(l.merge(ctx), l, slog.LevelError, msg, attrs...)
+	panic(msg)
+}
+
+// Log emits a log record with the given context, level and message and treats the args as key/value pairs to form log message attributes.
+func (l *Logger) Log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	wrap(l.merge(ctx), l, level, msg, args...)
+}
+
+// LogAttrs is a more efficient version of [Logger.Log] that accepts only Attrs.
+func (l *Logger) LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	wrap(l.merge(ctx), l, level, msg, args...)
 }
 
 // Base returns the underlying [slog.Logger].
@@ -163,42 +400,56 @@ func (l *Logger) Handler() slog.Handler {
 }
 
 func wrap(ctx context.Context, logger *Logger, level slog.Level, msg string, args ...any) {
-	if !logger.Handler().Enabled(ctx, level) {
+	if !enabled(ctx, logger.Handler(), level) {
 		return
 	}
 
-	var pcs [1]uintptr
-	runtime.Callers(3, pcs[:]) // skip [Callers, Infof, wrapf]
-	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	pc := callerPC(2 + logger.skip) // skip [wrap, Info]
+	r := slog.NewRecord(time.Now(), level, msg, pc)
 	r.Add(args...)
 	_ = logger.Handler().Handle(ctx, r)
 }
 
 // wrapf is like wrap, but uses fmt.Sprintf to format the message.
-// NOTE: args are passed to fmt.Sprintf, not as [slog.Attr].
+// NOTE: args are passed to fmt.Sprintf, except for any trailing [slog.Attr]
+// args, which are added to the record as attributes.
 func wrapf(ctx context.Context, logger *Logger, level slog.Level, format string, args ...any) {
-	if !logger.Handler().Enabled(ctx, level) {
+	if !enabled(ctx, logger.Handler(), level) {
 		return
 	}
 
-	var pcs [1]uintptr
-	runtime.Callers(3, pcs[:]) // skip [Callers, Infof, wrapf]
-	r := slog.NewRecord(time.Now(), level, fmt.Sprintf(format, args...), pcs[0])
+	msg, attrs := sprintf(format, args)
+	pc := callerPC(2 + logger.skip) // skip [wrapf, Infof]
+	r := slog.NewRecord(time.Now(), level, msg, pc)
+	r.Add(attrs...)
 	_ = logger.Handler().Handle(ctx, r)
 }
 
+// sprintf formats the args with fmt.Sprintf, except for any trailing
+// [slog.Attr] args, which are returned separately.
+func sprintf(format string, args []any) (string, []any) {
+	n := len(args)
+	for n > 0 {
+		if _, ok := args[n-1].(slog.Attr); !ok {
+			break
+		}
+		n--
+	}
+	return fmt.Sprintf(format, args[:n]...), args[n:]
+}
+
 type loggerKey struct{}
 
 func WithLogger(ctx context.Context, logger *Logger) context.Context {
-	return context.WithValue(ctx, loggerKey{}, logger.Logger)
+	l := *logger
+	// The caller skip only applies to the functions wrapping this logger.
+	l.skip = 0
+	return context.WithValue(ctx, loggerKey{}, &l)
 }
 
 func FromContext(ctx context.Context) *Logger {
-	if logger, ok := ctx.Value(loggerKey{}).(slog.Logger); ok {
-		return &Logger{
-			ctx:    ctx,
-			Logger: logger,
-		}
+	if logger, ok := ctx.Value(loggerKey{}).(*Logger); ok {
+		return logger.with(ctx, &logger.Logger)
 	}
 	return NewLoggerWithContext(ctx, nil)
 }
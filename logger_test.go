@@ -243,3 +243,59 @@ func TestContext(t *testing.T) {
 		})
 	}
 }
+
+func TestLoggerMergeContext(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := New(slog.NewTextHandler(b, testopts))
+
+	bound := WithValues(context.Background(), "a", "bound", "b", "bound")
+	bound = WithLevel(bound, slog.LevelDebug)
+	call := WithValues(context.Background(), "b", "call", "c", "call")
+
+	for _, tc := range []struct {
+		name string
+		fn   func(l *Logger)
+		want string
+	}{
+		{"Info", func(l *Logger) { l.Info("hello") }, "level=INFO msg=hello a=bound b=bound\n"},
+		{"InfoContext", func(l *Logger) { l.InfoContext(call, "hello") }, "level=INFO msg=hello a=bound b=call c=call\n"},
+		{"InfoContextf", func(l *Logger) { l.InfoContextf(call, "hello %d", 1) }, "level=INFO msg=\"hello 1\" a=bound b=call c=call\n"},
+		{"DebugContext", func(l *Logger) { l.DebugContext(call, "hello") }, "level=DEBUG msg=hello a=bound b=call c=call\n"},
+		{"Log", func(l *Logger) { l.Log(call, slog.LevelWarn, "hello") }, "level=WARN msg=hello a=bound b=call c=call\n"},
+		{"LogAttrs", func(l *Logger) { l.LogAttrs(call, slog.LevelWarn, "hello", slog.Int("d", 1)) }, "level=WARN msg=hello d=1 a=bound b=call c=call\n"},
+		{"derived", func(l *Logger) { l.InfoContext(WithValues(bound, "c", "call"), "hello") }, "level=INFO msg=hello a=bound b=bound c=call\n"},
+		{"WithContext", func(l *Logger) { l.WithContext(call).Info("hello") }, "level=INFO msg=hello b=call c=call\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b.Reset()
+			tc.fn(log.WithContext(bound))
+			if got := b.String(); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLoggerfAttrs(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := New(slog.NewTextHandler(b, testopts))
+
+	for _, tc := range []struct {
+		name string
+		fn   func()
+		want string
+	}{
+		{"no attrs", func() { log.Infof("hello %s", "world") }, "level=INFO msg=\"hello world\"\n"},
+		{"attrs", func() { log.Infof("hello %s", "world", slog.Int("a", 1), slog.Bool("b", true)) }, "level=INFO msg=\"hello world\" a=1 b=true\n"},
+		{"only attrs", func() { log.Warnf("hello", slog.Int("a", 1)) }, "level=WARN msg=hello a=1\n"},
+		{"non-trailing attr", func() { log.Errorf("hello %v %s", slog.Int("a", 1), "world") }, "level=ERROR msg=\"hello a=1 world\"\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b.Reset()
+			tc.fn()
+			if got := b.String(); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
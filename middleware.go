@@ -0,0 +1,103 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// HandleFunc handles a record, like [slog.Handler.Handle].
+type HandleFunc func(ctx context.Context, r slog.Record) error
+
+// Middleware wraps a HandleFunc, e.g. to change records before calling next,
+// or to drop them by not calling it.
+type Middleware func(next HandleFunc) HandleFunc
+
+// ChainHandler is a slog.Handler that passes records through a chain of
+// [Middleware] before writing them to a base handler.
+//
+// Attrs and groups added with WithAttrs and WithGroup are not applied to the
+// base handler. Instead, they are added to each record, nested in their
+// groups, so middleware sees all of the record's attrs.
+type ChainHandler struct {
+	base   slog.Handler
+	handle HandleFunc
+	scope  []scope
+}
+
+// Chain returns a handler that passes records through mw, in order, then
+// writes them to base.
+func Chain(base slog.Handler, mw ...Middleware) *ChainHandler {
+	handle := base.Handle
+	for _, m := range slices.Backward(mw) {
+		handle = m(handle)
+	}
+	return &ChainHandler{base: base, handle: handle}
+}
+
+func (h *ChainHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.base.Enabled(ctx, level)
+}
+
+func (h *ChainHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handle(ctx, scopeRecord(h.scope, r))
+}
+
+func (h *ChainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &ChainHandler{base: h.base, handle: h.handle, scope: append(slices.Clip(h.scope), scope{attrs: attrs})}
+}
+
+func (h *ChainHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &ChainHandler{base: h.base, handle: h.handle, scope: append(slices.Clip(h.scope), scope{group: name})}
+}
+
+// Flush implements [Flusher] by flushing the base handler.
+func (h *ChainHandler) Flush(ctx context.Context) error {
+	return Flush(ctx, h.base)
+}
+
+// Close implements [Closer] by closing the base handler.
+func (h *ChainHandler) Close(ctx context.Context) error {
+	return Close(ctx, h.base)
+}
+
+// ReplaceAttrs returns middleware that replaces each non-group attr of a
+// record with the result of f, like [slog.HandlerOptions.ReplaceAttr].
+// groups are the groups the attr is nested in. Attrs that f replaces with an
+// empty attr are removed.
+func ReplaceAttrs(f func(groups []string, a slog.Attr) slog.Attr) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, r slog.Record) error {
+			nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+			nr.AddAttrs(replaceAttrs(nil, recordAttrs(r), f)...)
+			return next(ctx, nr)
+		}
+	}
+}
+
+// replaceAttrs applies f to attrs, recursing into groups.
+func replaceAttrs(groups []string, attrs []slog.Attr, f func([]string, slog.Attr) slog.Attr) []slog.Attr {
+	replaced := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup {
+			path := groups
+			if a.Key != "" {
+				path = append(slices.Clip(groups), a.Key)
+			}
+			a.Value = slog.GroupValue(replaceAttrs(path, a.Value.Group(), f)...)
+		} else {
+			a = f(groups, a)
+		}
+		if !a.Equal(slog.Attr{}) {
+			replaced = append(replaced, a)
+		}
+	}
+	return replaced
+}
@@ -0,0 +1,59 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	b := new(syncBuffer)
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx context.Context, r slog.Record) error {
+				order = append(order, name)
+				return next(ctx, r)
+			}
+		}
+	}
+	enrich := func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, r slog.Record) error {
+			r.AddAttrs(slog.String("service", "test"))
+			return next(ctx, r)
+		}
+	}
+	drop := func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, r slog.Record) error {
+			if strings.HasPrefix(r.Message, "drop") {
+				return nil
+			}
+			return next(ctx, r)
+		}
+	}
+	upper := ReplaceAttrs(func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == "remove" {
+			return slog.Attr{}
+		}
+		if a.Value.Kind() == slog.KindString {
+			a.Value = slog.StringValue(strings.Join(append(groups, strings.ToUpper(a.Value.String())), "/"))
+		}
+		return a
+	})
+
+	log := New(Chain(slog.NewTextHandler(b, testopts), trace("one"), trace("two"), drop, enrich, upper))
+	ctx := WithValues(context.Background(), "ctx", "v")
+	log.InfoContext(ctx, "hello", "a", "b")
+	log.InfoContext(ctx, "drop me")
+	log.With("c", "d", "remove", "x").WithGroup("g").With("e", "f").InfoContext(ctx, "nested", "h", "i")
+
+	want := "level=INFO msg=hello a=B ctx=V service=TEST\n" +
+		"level=INFO msg=nested c=D g.e=g/F g.h=g/I g.ctx=g/V service=TEST\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if want, got := "one two one two one two", strings.Join(order, " "); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
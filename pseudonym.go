@@ -0,0 +1,137 @@
+package clog
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+)
+
+// defaultTokenLength is the token length used if [PseudonymizerOptions.Length] is not set.
+const defaultTokenLength = 16
+
+// PseudonymizerOptions are options for a [Pseudonymizer].
+type PseudonymizerOptions struct {
+	// Keys are the keys of attrs to pseudonymize, as described by
+	// [RedactOptions.Keys], e.g. "user_id", "email" or "*_ip".
+	Keys []string
+
+	// KeyID identifies Secret in tokens, so tokens made with different
+	// secrets can be told apart after it is rotated.
+	KeyID string
+
+	// Secret is the HMAC key used to make tokens. If empty, a random secret
+	// is used, so tokens only match within the same process.
+	Secret []byte
+
+	// Length is the number of bytes of the HMAC included in tokens.
+	// Defaults to 16.
+	Length int
+}
+
+// Pseudonymizer replaces the values of identifying attrs, such as user IDs,
+// emails and IP addresses, with tokens derived from them with an HMAC.
+// The same value always has the same token for a given secret, so records
+// can still be correlated without logging the value itself.
+//
+// Tokens have the form "<key ID>:<hex HMAC>", or just the HMAC if there is
+// no key ID.
+type Pseudonymizer struct {
+	keys   keyPatterns
+	length int
+	key    atomic.Pointer[hmacKey]
+}
+
+type hmacKey struct {
+	id     string
+	secret []byte
+}
+
+// NewPseudonymizer returns a Pseudonymizer configured with opts.
+// If opts is nil, the default options are used, and no attrs are pseudonymized.
+func NewPseudonymizer(opts *PseudonymizerOptions) *Pseudonymizer {
+	if opts == nil {
+		opts = &PseudonymizerOptions{}
+	}
+	p := &Pseudonymizer{
+		keys:   newKeyPatterns(opts.Keys),
+		length: opts.Length,
+	}
+	if p.length <= 0 || p.length > sha256.Size {
+		p.length = defaultTokenLength
+	}
+	secret := opts.Secret
+	if len(secret) == 0 {
+		secret = make([]byte, sha256.Size)
+		rand.Read(secret)
+	}
+	p.Rotate(opts.KeyID, secret)
+	return p
+}
+
+// Rotate replaces the secret used to make tokens, and its ID.
+// It is safe to call while records are being logged.
+func (p *Pseudonymizer) Rotate(keyID string, secret []byte) {
+	p.key.Store(&hmacKey{id: keyID, secret: slices.Clone(secret)})
+}
+
+// Token returns the token for value, e.g. to find the records of a user.
+func (p *Pseudonymizer) Token(value string) string {
+	k := p.key.Load()
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write([]byte(value))
+	token := hex.EncodeToString(mac.Sum(nil)[:p.length])
+	if k.id == "" {
+		return token
+	}
+	return k.id + ":" + token
+}
+
+// Middleware returns middleware, for use with [Chain], that pseudonymizes
+// the record's attrs, including attrs added with Logger.With and, when used
+// under a [Handler], context values. If a group's key matches, all of the
+// values in it are pseudonymized.
+func (p *Pseudonymizer) Middleware() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, r slog.Record) error {
+			nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+			nr.AddAttrs(p.attrs(nil, recordAttrs(r), false)...)
+			return next(ctx, nr)
+		}
+	}
+}
+
+// ReplaceAttr pseudonymizes a, for use as [slog.HandlerOptions.ReplaceAttr].
+func (p *Pseudonymizer) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	return p.attr(groups, a, false)
+}
+
+func (p *Pseudonymizer) attrs(groups []string, attrs []slog.Attr, all bool) []slog.Attr {
+	replaced := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		replaced[i] = p.attr(groups, a, all)
+	}
+	return replaced
+}
+
+// attr returns a with its value pseudonymized if its path matches or all is true,
+// or with any matching attrs in it pseudonymized.
+func (p *Pseudonymizer) attr(groups []string, a slog.Attr, all bool) slog.Attr {
+	v := a.Value.Resolve()
+	path := groups
+	if a.Key != "" {
+		path = append(slices.Clip(groups), a.Key)
+		all = all || p.keys.match(path)
+	}
+	if v.Kind() == slog.KindGroup {
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(p.attrs(path, v.Group(), all)...)}
+	}
+	if all {
+		return slog.String(a.Key, p.Token(v.String()))
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
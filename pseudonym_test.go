@@ -0,0 +1,85 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestPseudonymizer(t *testing.T) {
+	p := NewPseudonymizer(&PseudonymizerOptions{
+		Keys:   []string{"user_id", "email", "*_ip", "client"},
+		KeyID:  "k1",
+		Secret: []byte("secret"),
+		Length: 4,
+	})
+	b := new(bytes.Buffer)
+	log := New(Chain(slog.NewTextHandler(b, testopts), p.Middleware()))
+
+	ctx := WithValues(context.Background(), "user_id", "u123")
+	log.With("email", "jane@example.com").InfoContext(ctx, "hello",
+		"remote_ip", netip.MustParseAddr("10.0.0.1"),
+		"path", "/",
+		slog.Group("client", "name", "curl", "version", 8),
+	)
+
+	want := "level=INFO msg=hello" +
+		" email=" + p.Token("jane@example.com") +
+		" remote_ip=" + p.Token("10.0.0.1") +
+		" path=/" +
+		" client.name=" + p.Token("curl") +
+		" client.version=" + p.Token("8") +
+		" user_id=" + p.Token("u123") + "\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if strings.Contains(b.String(), "u123") || strings.Contains(b.String(), "jane") {
+		t.Errorf("want values pseudonymized, got %q", b.String())
+	}
+}
+
+func TestPseudonymizerToken(t *testing.T) {
+	p := NewPseudonymizer(&PseudonymizerOptions{KeyID: "k1", Secret: []byte("one")})
+	tok := p.Token("u123")
+	if !strings.HasPrefix(tok, "k1:") || len(tok) != len("k1:")+2*defaultTokenLength {
+		t.Errorf("want a k1 token, got %q", tok)
+	}
+	if got := p.Token("u123"); got != tok {
+		t.Errorf("want stable tokens, got %q and %q", tok, got)
+	}
+	if got := p.Token("u124"); got == tok {
+		t.Errorf("want different tokens for different values, got %q", got)
+	}
+
+	p.Rotate("k2", []byte("two"))
+	rotated := p.Token("u123")
+	if !strings.HasPrefix(rotated, "k2:") || rotated[3:] == tok[3:] {
+		t.Errorf("want a new k2 token, got %q", rotated)
+	}
+
+	// Tokens from different pseudonymizers with the same secret match.
+	if got := NewPseudonymizer(&PseudonymizerOptions{KeyID: "k2", Secret: []byte("two")}).Token("u123"); got != rotated {
+		t.Errorf("want %q, got %q", rotated, got)
+	}
+	// Random secrets don't.
+	if NewPseudonymizer(nil).Token("u123") == NewPseudonymizer(nil).Token("u123") {
+		t.Error("want different tokens with random secrets")
+	}
+}
+
+func TestPseudonymizerReplaceAttr(t *testing.T) {
+	p := NewPseudonymizer(&PseudonymizerOptions{Keys: []string{"user"}, Secret: []byte("secret")})
+	b := new(bytes.Buffer)
+	log := New(slog.NewTextHandler(b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			return p.ReplaceAttr(groups, testopts.ReplaceAttr(groups, a))
+		},
+	}))
+	log.Info("hello", "user", "jane")
+	if want, got := "level=INFO msg=hello user="+p.Token("jane")+"\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,256 @@
+package clog
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RateLimitKey is what a [RateLimiter] limits records by.
+type RateLimitKey int
+
+const (
+	// RateLimitByMessage limits records with the same message.
+	RateLimitByMessage RateLimitKey = iota
+	// RateLimitBySource limits records logged from the same source line.
+	RateLimitBySource
+	// RateLimitByContext limits records with the same value of
+	// [RateLimiterOptions.ContextKey] in their context.
+	RateLimitByContext
+)
+
+const (
+	// defaultRate is the rate used if [RateLimiterOptions.Rate] is not set.
+	defaultRate = 10
+
+	// defaultReportInterval is the interval used if [RateLimiterOptions.ReportInterval] is not set.
+	defaultReportInterval = time.Minute
+
+	// defaultMaxRateLimitKeys is the limit used if [RateLimiterOptions.MaxKeys] is not set.
+	defaultMaxRateLimitKeys = 10000
+)
+
+// RateLimiterOptions are options for a [RateLimiter].
+// A zero RateLimiterOptions consists entirely of default values.
+type RateLimiterOptions struct {
+	// Rate is the number of records per second allowed for each key.
+	// Defaults to 10.
+	Rate float64
+
+	// Burst is the number of records allowed at once for each key.
+	// Defaults to Rate, and at least 1.
+	Burst int
+
+	// By is what records are limited by. Defaults to RateLimitByMessage.
+	By RateLimitKey
+
+	// ContextKey is the key of the context value, set with [WithValues] or an
+	// [Extractor], used with [RateLimitByContext].
+	ContextKey string
+
+	// MaxKeys is the maximum number of keys tracked at once. When a new key
+	// is seen with this many tracked, the least recently used key is
+	// forgotten, so its limit starts again. Defaults to 10000.
+	MaxKeys int
+
+	// LimitKeyless limits records without a key (those without the context
+	// value, or without a source location) as if they shared one key.
+	// By default, they are not limited.
+	LimitKeyless bool
+
+	// ReportInterval is how often a record reporting the number of suppressed
+	// records is written, if any were suppressed. Defaults to one minute.
+	ReportInterval time.Duration
+
+	// OnError, if set, is called with any error returned by the inner handler
+	// when writing a report.
+	OnError func(error)
+}
+
+// RateLimiter is a slog.Handler that limits the rate of records for each key,
+// such as a message or a tenant, using a token bucket.
+//
+// Suppressed records are counted, and a warning reporting the count for each
+// key is written every report interval, and when the handler is flushed.
+type RateLimiter struct {
+	h slog.Handler
+	l *rateLimiter
+}
+
+// rateLimiter is the state shared by a RateLimiter and the handlers derived from it.
+type rateLimiter struct {
+	h    slog.Handler
+	opts RateLimiterOptions
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element // of *bucket
+	// lru orders the buckets from most to least recently used.
+	lru        *list.List
+	suppressed map[string]int
+	timer      *time.Timer
+}
+
+// bucket is a token bucket for a single key.
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a handler that writes records to h, limited by opts.
+// If opts is nil, the default options are used.
+func NewRateLimiter(h slog.Handler, opts *RateLimiterOptions) *RateLimiter {
+	if opts == nil {
+		opts = &RateLimiterOptions{}
+	}
+	o := *opts
+	if o.Rate <= 0 {
+		o.Rate = defaultRate
+	}
+	return &RateLimiter{h: h, l: &rateLimiter{
+		h:          h,
+		opts:       o,
+		buckets:    map[string]*list.Element{},
+		lru:        list.New(),
+		suppressed: map[string]int{},
+	}}
+}
+
+func (h *RateLimiter) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+func (h *RateLimiter) Handle(ctx context.Context, r slog.Record) error {
+	key, ok := h.l.key(ctx, r)
+	if (ok || h.l.opts.LimitKeyless) && !h.l.allow(key, recordTime(r)) {
+		return nil
+	}
+	return h.h.Handle(ctx, r)
+}
+
+func (h *RateLimiter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RateLimiter{h: h.h.WithAttrs(attrs), l: h.l}
+}
+
+func (h *RateLimiter) WithGroup(name string) slog.Handler {
+	return &RateLimiter{h: h.h.WithGroup(name), l: h.l}
+}
+
+// Flush implements [Flusher] by writing a report of the records suppressed
+// so far, then flushing the inner handler.
+func (h *RateLimiter) Flush(ctx context.Context) error {
+	return errors.Join(h.l.report(ctx), Flush(ctx, h.l.h))
+}
+
+// Close implements [Closer] by writing a report of the records suppressed
+// so far, then closing the inner handler.
+func (h *RateLimiter) Close(ctx context.Context) error {
+	return errors.Join(h.l.report(ctx), Close(ctx, h.l.h))
+}
+
+// key returns the key r is limited by, if it has one.
+func (l *rateLimiter) key(ctx context.Context, r slog.Record) (string, bool) {
+	switch l.opts.By {
+	case RateLimitBySource:
+		if r.PC == 0 {
+			return "", false
+		}
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		return fmt.Sprintf("%s:%d", f.File, f.Line), true
+	case RateLimitByContext:
+		if v, ok := contextValue(ctx, l.opts.ContextKey); ok {
+			return v.String(), true
+		}
+		return "", false
+	default:
+		return r.Message, true
+	}
+}
+
+// allow reports whether a record with key can be written at now,
+// counting it as suppressed if not.
+func (l *rateLimiter) allow(key string, now time.Time) bool {
+	burst := float64(l.opts.Burst)
+	if burst <= 0 {
+		burst = max(l.opts.Rate, 1)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var b *bucket
+	if el, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(el)
+		b = el.Value.(*bucket)
+	} else {
+		maxKeys := l.opts.MaxKeys
+		if maxKeys <= 0 {
+			maxKeys = defaultMaxRateLimitKeys
+		}
+		if l.lru.Len() >= maxKeys {
+			oldest := l.lru.Back()
+			l.lru.Remove(oldest)
+			delete(l.buckets, oldest.Value.(*bucket).key)
+		}
+		b = &bucket{key: key, tokens: burst, last: now}
+		l.buckets[key] = l.lru.PushFront(b)
+	}
+	if now.After(b.last) {
+		b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*l.opts.Rate)
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+
+	l.suppressed[key]++
+	if l.timer == nil {
+		interval := l.opts.ReportInterval
+		if interval <= 0 {
+			interval = defaultReportInterval
+		}
+		l.timer = time.AfterFunc(interval, func() {
+			if err := l.report(context.Background()); err != nil && l.opts.OnError != nil {
+				l.opts.OnError(err)
+			}
+		})
+	}
+	return false
+}
+
+// report writes a warning for each key with suppressed records.
+func (l *rateLimiter) report(ctx context.Context) error {
+	l.mu.Lock()
+	suppressed := l.suppressed
+	l.suppressed = map[string]int{}
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	l.mu.Unlock()
+
+	keys := make([]string, 0, len(suppressed))
+	for key := range suppressed {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var errs []error
+	for _, key := range keys {
+		if !l.h.Enabled(ctx, slog.LevelWarn) {
+			break
+		}
+		r := slog.NewRecord(time.Now(), slog.LevelWarn, "log records suppressed by rate limit", 0)
+		r.AddAttrs(slog.String("rate_limit_key", key), slog.Int("suppressed", suppressed[key]))
+		if err := l.h.Handle(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
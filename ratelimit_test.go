@@ -0,0 +1,148 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	b := new(syncBuffer)
+	h := NewRateLimiter(slog.NewTextHandler(b, testopts), &RateLimiterOptions{
+		Rate:           1,
+		Burst:          2,
+		ReportInterval: time.Hour,
+	})
+	ctx := context.Background()
+	start := time.Now()
+	handle := func(offset time.Duration, msg string) {
+		t.Helper()
+		if err := h.Handle(ctx, slog.NewRecord(start.Add(offset), slog.LevelInfo, msg, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 4 {
+		handle(0, "a")
+	}
+	handle(0, "b")
+	// One token is added each second.
+	handle(time.Second, "a")
+	handle(time.Second, "a")
+
+	want := "level=INFO msg=a\nlevel=INFO msg=a\nlevel=INFO msg=b\nlevel=INFO msg=a\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	b.b.Reset()
+	if err := h.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want = `level=WARN msg="log records suppressed by rate limit" rate_limit_key=a suppressed=3` + "\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRateLimiterByContext(t *testing.T) {
+	b := new(syncBuffer)
+	log := New(NewRateLimiter(slog.NewTextHandler(b, testopts), &RateLimiterOptions{
+		Rate:       1,
+		By:         RateLimitByContext,
+		ContextKey: "tenant",
+	}))
+
+	noisy := WithValues(context.Background(), "tenant", "noisy")
+	quiet := WithValues(context.Background(), "tenant", "quiet")
+	log.InfoContext(noisy, "one")
+	log.InfoContext(noisy, "two")
+	log.InfoContext(quiet, "three")
+
+	want := "level=INFO msg=one tenant=noisy\nlevel=INFO msg=three tenant=quiet\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRateLimiterBySource(t *testing.T) {
+	b := new(syncBuffer)
+	log := New(NewRateLimiter(slog.NewTextHandler(b, testopts), &RateLimiterOptions{
+		Rate:           1,
+		By:             RateLimitBySource,
+		ReportInterval: 10 * time.Millisecond,
+	}))
+	for i := range 3 {
+		log.Info("loop", "i", i)
+	}
+	log.Info("other")
+
+	waitFor(t, func() bool { return strings.Contains(b.String(), "suppressed=2") })
+	want := "level=INFO msg=loop i=0\nlevel=INFO msg=other\n"
+	if got, _, _ := strings.Cut(b.String(), "level=WARN"); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if !strings.Contains(b.String(), "ratelimit_test.go:") {
+		t.Errorf("want the source in the report, got %q", b.String())
+	}
+}
+
+func TestRateLimiterKeyless(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		by    RateLimitKey
+		limit bool
+		want  int
+	}{
+		{"context", RateLimitByContext, false, 3},
+		{"context limited", RateLimitByContext, true, 1},
+		{"source", RateLimitBySource, false, 3},
+		{"source limited", RateLimitBySource, true, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(syncBuffer)
+			h := NewRateLimiter(slog.NewTextHandler(b, testopts), &RateLimiterOptions{
+				Rate:         1,
+				By:           tc.by,
+				ContextKey:   "tenant",
+				LimitKeyless: tc.limit,
+			})
+			// Neither a tenant nor a source location.
+			for range 3 {
+				if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "startup", 0)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := strings.Count(b.String(), "msg=startup"); got != tc.want {
+				t.Errorf("want %d records, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRateLimiterMaxKeys(t *testing.T) {
+	b := new(syncBuffer)
+	h := NewRateLimiter(slog.NewTextHandler(b, testopts), &RateLimiterOptions{
+		Rate:           1,
+		MaxKeys:        2,
+		ReportInterval: time.Hour,
+	})
+	ctx := context.Background()
+	now := time.Now()
+	for _, msg := range []string{"a", "b", "a", "c", "a", "b"} {
+		if err := h.Handle(ctx, slog.NewRecord(now, slog.LevelInfo, msg, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// c evicts b, the least recently used key, so b's limit starts again.
+	want := "level=INFO msg=a\nlevel=INFO msg=b\nlevel=INFO msg=c\nlevel=INFO msg=b\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	h.l.mu.Lock()
+	defer h.l.mu.Unlock()
+	if got := len(h.l.buckets); got != 2 {
+		t.Errorf("want 2 keys tracked, got %d", got)
+	}
+}
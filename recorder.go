@@ -0,0 +1,148 @@
+package clog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+// defaultRecorderSize is the number of records kept if [RecorderOptions.Size] is not set.
+const defaultRecorderSize = 100
+
+// RecorderOptions are options for a [Recorder].
+// A zero RecorderOptions consists entirely of default values.
+type RecorderOptions struct {
+	// Size is the maximum number of records kept. Defaults to 100.
+	Size int
+
+	// Level is the minimum level of records kept.
+	// By default, records at all levels are kept.
+	Level slog.Leveler
+
+	// Trigger is the level at or above which the kept records are written.
+	// Defaults to LevelError.
+	Trigger slog.Leveler
+}
+
+// Recorder is a slog.Handler that acts as a flight recorder: it keeps the
+// most recent records that the inner handler is not enabled for (e.g. debug
+// records in production), and writes them to the inner handler when a
+// record at or above the trigger level is handled.
+//
+// Records are kept with a [Snapshot] of their context, so the written
+// records include the context values from when they were logged.
+type Recorder struct {
+	h slog.Handler
+	r *recorder
+}
+
+// recorder is the state shared by a Recorder and the handlers derived from it.
+type recorder struct {
+	h    slog.Handler
+	opts RecorderOptions
+
+	mu      sync.Mutex
+	entries []recorderEntry
+	next    int
+}
+
+type recorderEntry struct {
+	ctx context.Context
+	h   slog.Handler
+	r   slog.Record
+}
+
+// NewRecorder returns a flight recorder that writes to h.
+// If opts is nil, the default options are used.
+func NewRecorder(h slog.Handler, opts *RecorderOptions) *Recorder {
+	if opts == nil {
+		opts = &RecorderOptions{}
+	}
+	size := opts.Size
+	if size <= 0 {
+		size = defaultRecorderSize
+	}
+	return &Recorder{h: h, r: &recorder{
+		h:       h,
+		opts:    *opts,
+		entries: make([]recorderEntry, 0, size),
+	}}
+}
+
+func (h *Recorder) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.r.opts.Level == nil || level >= h.r.opts.Level.Level() {
+		return true
+	}
+	return enabled(ctx, h.h, level)
+}
+
+func (h *Recorder) Handle(ctx context.Context, r slog.Record) error {
+	trigger := LevelError
+	if h.r.opts.Trigger != nil {
+		trigger = h.r.opts.Trigger.Level()
+	}
+	if r.Level >= trigger {
+		return errors.Join(h.Dump(ctx), h.h.Handle(ctx, r))
+	}
+	if enabled(ctx, h.h, r.Level) {
+		return h.h.Handle(ctx, r)
+	}
+	h.r.add(recorderEntry{ctx: Snapshot(ctx), h: h.h, r: snapshotRecord(r)})
+	return nil
+}
+
+func (h *Recorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Recorder{h: h.h.WithAttrs(attrs), r: h.r}
+}
+
+func (h *Recorder) WithGroup(name string) slog.Handler {
+	return &Recorder{h: h.h.WithGroup(name), r: h.r}
+}
+
+// Dump writes the kept records to the inner handler, oldest first,
+// and clears them.
+func (h *Recorder) Dump(ctx context.Context) error {
+	var errs []error
+	for _, e := range h.r.take() {
+		if err := e.h.Handle(e.ctx, e.r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush implements [Flusher] by flushing the inner handler.
+// Kept records are not written.
+func (h *Recorder) Flush(ctx context.Context) error {
+	return Flush(ctx, h.r.h)
+}
+
+// Close implements [Closer] by closing the inner handler.
+// Kept records are discarded.
+func (h *Recorder) Close(ctx context.Context) error {
+	h.r.take()
+	return Close(ctx, h.r.h)
+}
+
+// add keeps e, replacing the oldest entry if the buffer is full.
+func (r *recorder) add(e recorderEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) < cap(r.entries) {
+		r.entries = append(r.entries, e)
+		return
+	}
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+}
+
+// take returns the kept entries, oldest first, and clears them.
+func (r *recorder) take() []recorderEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := append(r.entries[r.next:], r.entries[:r.next]...)
+	r.entries = make([]recorderEntry, 0, cap(r.entries))
+	r.next = 0
+	return entries
+}
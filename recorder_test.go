@@ -0,0 +1,87 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	b := new(bytes.Buffer)
+	h := NewRecorder(slog.NewTextHandler(b, testopts), &RecorderOptions{Size: 2})
+	log := New(h)
+
+	ctx := WithValues(context.Background(), "a", "b")
+	log.DebugContext(ctx, "one")
+	log.DebugContext(ctx, "two")
+	log.With("c", "d").DebugContext(ctx, "three")
+	log.InfoContext(ctx, "four")
+	if want, got := "level=INFO msg=four a=b\n", b.String(); got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+
+	log.ErrorContext(ctx, "five")
+	want := "level=INFO msg=four a=b\n" +
+		"level=DEBUG msg=two a=b\n" +
+		"level=DEBUG msg=three c=d a=b\n" +
+		"level=ERROR msg=five a=b\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	// Kept records are only written once.
+	b.Reset()
+	log.ErrorContext(ctx, "six")
+	if want, got := "level=ERROR msg=six a=b\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRecorderOptions(t *testing.T) {
+	b := new(bytes.Buffer)
+	h := NewRecorder(slog.NewTextHandler(b, testopts), &RecorderOptions{
+		Level:   slog.LevelDebug,
+		Trigger: slog.LevelWarn,
+	})
+	log := New(h)
+
+	log.Trace("one")
+	log.Debug("two")
+	log.Warn("three")
+	want := "level=DEBUG msg=two\nlevel=WARN msg=three\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	b.Reset()
+	log.Debug("four")
+	if err := h.Dump(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "level=DEBUG msg=four\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRecorderWithLevel(t *testing.T) {
+	b := new(bytes.Buffer)
+	h := NewRecorder(slog.NewTextHandler(b, testopts), &RecorderOptions{Level: slog.LevelInfo})
+	log := New(h)
+
+	// Records enabled by WithLevel are written, not kept.
+	ctx := WithLevel(context.Background(), slog.LevelDebug)
+	if !h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("want debug enabled with WithLevel")
+	}
+	log.DebugContext(ctx, "one")
+	if want, got := "level=DEBUG msg=one\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if err := h.Dump(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "level=DEBUG msg=one\n", b.String(); got != want {
+		t.Errorf("want nothing kept, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package clog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Recover recovers from a panic, if any, and logs it at LevelError with the
+// context logger, along with the panic value, the stack trace and any values
+// added to the context with [WithValues].
+// Panics with [Exit], from Fatal with [PanicExit], are not recovered.
+// It must be called directly by a deferred function:
+//
+//	defer clog.Recover(ctx)
+func Recover(ctx context.Context) {
+	if r := recover(); r != nil {
+		if e, ok := r.(Exit); ok {
+			panic(e)
+		}
+		logPanic(ctx, r)
+	}
+}
+
+// RecoverAndRepanic is like [Recover], but panics again with the same value
+// after logging.
+//
+//	defer clog.RecoverAndRepanic(ctx)
+func RecoverAndRepanic(ctx context.Context) {
+	if r := recover(); r != nil {
+		if _, ok := r.(Exit); !ok {
+			logPanic(ctx, r)
+		}
+		panic(r)
+	}
+}
+
+// logPanic logs the recovered panic value r.
+func logPanic(ctx context.Context, r any) {
+	logger := FromContext(ctx)
+	h := logger.Handler()
+	if !enabled(ctx, h, slog.LevelError) {
+		return
+	}
+	if _, ok := h.(Handler); !ok {
+		// Make sure context values are logged.
+		h = NewHandler(h)
+	}
+
+	stack, pc := panicStack(4) // skip [Callers, panicStack, logPanic, Recover]
+	rec := slog.NewRecord(time.Now(), slog.LevelError, fmt.Sprintf("panic: %v", r), pc)
+	rec.AddAttrs(slog.Any("panic", r), slog.String("stack", stack))
+	_ = h.Handle(ctx, rec)
+}
+
+// panicStack returns the stack trace of the panicking goroutine without
+// runtime frames, and the program counter of the function that panicked.
+func panicStack(skip int) (string, uintptr) {
+	var pcs [64]uintptr
+	n := runtime.Callers(skip, pcs[:])
+
+	var panicPC uintptr
+	for _, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			panicPC = pc
+			break
+		}
+	}
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String(), panicPC
+}
@@ -0,0 +1,133 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+//go:noinline
+func panicky() {
+	panic("oh no")
+}
+
+func TestRecover(t *testing.T) {
+	b := new(bytes.Buffer)
+	// Use a logger without a clog.Handler, to check context values are still logged.
+	log := NewLogger(slog.New(slog.NewJSONHandler(b, &slog.HandlerOptions{
+		AddSource:   true,
+		ReplaceAttr: testopts.ReplaceAttr,
+	})))
+	ctx := WithLogger(context.Background(), log)
+	ctx = WithValues(ctx, "a", "b")
+
+	func() {
+		defer Recover(ctx)
+		panicky()
+	}()
+
+	var got struct {
+		Level  string `json:"level"`
+		Msg    string `json:"msg"`
+		Panic  string `json:"panic"`
+		Stack  string `json:"stack"`
+		A      string `json:"a"`
+		Source struct {
+			Function string `json:"function"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Level != "ERROR" || got.Msg != "panic: oh no" || got.Panic != "oh no" || got.A != "b" {
+		t.Errorf("unexpected record: %s", b.String())
+	}
+	if want := "github.com/chainguard-dev/clog.panicky"; got.Source.Function != want {
+		t.Errorf("want source %s, got %s", want, got.Source.Function)
+	}
+	if !strings.HasPrefix(got.Stack, "github.com/chainguard-dev/clog.panicky\n") {
+		t.Errorf("want stack to start at panicky, got %s", got.Stack)
+	}
+	if strings.Contains(got.Stack, "runtime.") {
+		t.Errorf("want no runtime frames, got %s", got.Stack)
+	}
+}
+
+func TestRecoverAndRepanic(t *testing.T) {
+	b := new(bytes.Buffer)
+	ctx := WithLogger(context.Background(), New(slog.NewTextHandler(b, testopts)))
+
+	defer func() {
+		if r := recover(); r != "oh no" {
+			t.Errorf("want repanic with %q, got %v", "oh no", r)
+		}
+		if !strings.Contains(b.String(), `msg="panic: oh no"`) {
+			t.Errorf("want panic to be logged, got %q", b.String())
+		}
+	}()
+	defer RecoverAndRepanic(ctx)
+	panicky()
+}
+
+func TestPanic(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := New(slog.NewTextHandler(b, testopts))
+
+	for _, tc := range []struct {
+		name      string
+		fn        func()
+		wantPanic string
+		want      string
+	}{
+		{"Panic", func() { log.Panic("hello", "a", 1) }, "hello", "level=ERROR msg=hello a=1\n"},
+		{"Panicf", func() { log.Panicf("hello %s", "world", slog.Int("a", 1)) }, "hello world", "level=ERROR msg=\"hello world\" a=1\n"},
+		{"PanicContext", func() { PanicContext(WithLogger(context.Background(), log), "hello") }, "hello", "level=ERROR msg=hello\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b.Reset()
+			defer func() {
+				if r := recover(); r != tc.wantPanic {
+					t.Errorf("want panic %q, got %v", tc.wantPanic, r)
+				}
+				if got := b.String(); got != tc.want {
+					t.Errorf("want %q, got %q", tc.want, got)
+				}
+			}()
+			tc.fn()
+		})
+	}
+}
+
+func TestRecoverExit(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		recover func(context.Context)
+	}{
+		{"recover", Recover},
+		{"repanic", RecoverAndRepanic},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			log := New(slog.NewTextHandler(b, testopts)).WithExitFunc(PanicExit).WithExitCode(3)
+			ctx := WithLogger(context.Background(), log)
+
+			var got any
+			func() {
+				defer func() { got = recover() }()
+				func() {
+					defer tc.recover(ctx)
+					FromContext(ctx).Fatal("bye")
+				}()
+			}()
+			if want := (Exit{Code: 3}); got != want {
+				t.Errorf("want panic %v, got %v", want, got)
+			}
+			if want := "level=ERROR+6 msg=bye\n"; b.String() != want {
+				t.Errorf("want %q, got %q", want, b.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package clog
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// DefaultRedactKeys are the keys redacted if [RedactOptions.Keys] is empty.
+var DefaultRedactKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"*token",
+	"authorization",
+	"cookie",
+	"set-cookie",
+	"*api_key",
+	"*apikey",
+	"private_key",
+}
+
+// RedactOptions are options for [Redact] and [RedactReplaceAttr].
+// A zero RedactOptions consists entirely of default values.
+type RedactOptions struct {
+	// Keys are the keys of attrs to redact. Each is a key name or a
+	// [path.Match] pattern, such as "*token", matched case-insensitively.
+	// Keys with dots match the end of the attr's group path, e.g.
+	// "headers.authorization". Defaults to [DefaultRedactKeys].
+	Keys []string
+
+	// Placeholder is logged in place of redacted values.
+	// Defaults to "[REDACTED]".
+	Placeholder string
+}
+
+// redactor redacts attrs according to its options.
+type redactor struct {
+	keys        keyPatterns
+	placeholder string
+}
+
+// keyPatterns are attr key patterns, split on dots, as described by [RedactOptions.Keys].
+type keyPatterns [][]string
+
+func newKeyPatterns(keys []string) keyPatterns {
+	patterns := make(keyPatterns, len(keys))
+	for i, k := range keys {
+		patterns[i] = strings.Split(strings.ToLower(k), ".")
+	}
+	return patterns
+}
+
+func newRedactor(opts *RedactOptions) *redactor {
+	if opts == nil {
+		opts = &RedactOptions{}
+	}
+	keys := opts.Keys
+	if len(keys) == 0 {
+		keys = DefaultRedactKeys
+	}
+	return &redactor{
+		keys:        newKeyPatterns(keys),
+		placeholder: cmp.Or(opts.Placeholder, redactedPlaceholder),
+	}
+}
+
+// Redact returns middleware, for use with [Chain], that redacts the values
+// of attrs with the keys in opts. This includes attrs added with
+// Logger.With and, when used under a [Handler], context values.
+// Groups are redacted as a whole, and maps with string keys, like
+// http.Header, have their matching entries redacted.
+// If opts is nil, the default options are used.
+func Redact(opts *RedactOptions) Middleware {
+	rd := newRedactor(opts)
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, r slog.Record) error {
+			nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+			nr.AddAttrs(rd.attrs(nil, recordAttrs(r))...)
+			return next(ctx, nr)
+		}
+	}
+}
+
+// RedactReplaceAttr returns a function, for use as
+// [slog.HandlerOptions.ReplaceAttr], that redacts the values of attrs with
+// the keys in opts. slog doesn't call ReplaceAttr for groups, so unlike
+// [Redact], keys only match attrs that aren't groups.
+// If opts is nil, the default options are used.
+func RedactReplaceAttr(opts *RedactOptions) func(groups []string, a slog.Attr) slog.Attr {
+	rd := newRedactor(opts)
+	return func(groups []string, a slog.Attr) slog.Attr {
+		return rd.attr(groups, a)
+	}
+}
+
+func (rd *redactor) attrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = rd.attr(groups, a)
+	}
+	return redacted
+}
+
+// attr returns a, redacted if its path matches, or with any matching attrs in it redacted.
+func (rd *redactor) attr(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == "" {
+		// The attrs of groups with empty keys are inlined.
+		if v := a.Value.Resolve(); v.Kind() == slog.KindGroup {
+			return slog.Attr{Value: slog.GroupValue(rd.attrs(groups, v.Group())...)}
+		}
+		return a
+	}
+	p := append(slices.Clip(groups), a.Key)
+	if rd.keys.match(p) {
+		return slog.String(a.Key, rd.placeholder)
+	}
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(rd.attrs(p, v.Group())...)}
+	case slog.KindAny:
+		if g, ok := rd.mapValue(p, v.Any()); ok {
+			return slog.Attr{Key: a.Key, Value: g}
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// mapValue returns m as a group with matching entries redacted,
+// if m is a map with string keys and has any.
+func (rd *redactor) mapValue(p []string, m any) (slog.Value, bool) {
+	rv := reflect.ValueOf(m)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return slog.Value{}, false
+	}
+	keys := rv.MapKeys()
+	if !slices.ContainsFunc(keys, func(k reflect.Value) bool { return rd.keys.match(append(slices.Clip(p), k.String())) }) {
+		return slog.Value{}, false
+	}
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+	attrs := make([]slog.Attr, len(keys))
+	for i, k := range keys {
+		attrs[i] = rd.attr(p, slog.Any(k.String(), rv.MapIndex(k).Interface()))
+	}
+	return slog.GroupValue(attrs...), true
+}
+
+// match reports whether the attr with path p matches any of the patterns.
+func (k keyPatterns) match(p []string) bool {
+	for _, patterns := range k {
+		if len(patterns) > len(p) {
+			continue
+		}
+		tail := p[len(p)-len(patterns):]
+		matched := true
+		for i, pattern := range patterns {
+			if ok, _ := path.Match(pattern, strings.ToLower(tail[i])); !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
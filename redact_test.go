@@ -0,0 +1,99 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := New(Chain(slog.NewJSONHandler(b, testopts), Redact(&RedactOptions{
+		Keys: []string{"password", "*token", "request.headers.authorization", "api"},
+	})))
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc")
+	header.Set("Accept", "text/plain")
+	ctx := WithValues(context.Background(), "request", slog.GroupValue(slog.Any("headers", header)))
+	// Headers outside request aren't redacted.
+	ctx = WithValues(ctx, "headers", header)
+
+	log.With("access_token", "abc").InfoContext(ctx, "hello",
+		"password", "hunter2",
+		"user", "jane",
+		slog.Group("api", "key", "abc"),
+		slog.Group("g", "password", "hunter2"),
+	)
+
+	want := `{"level":"INFO","msg":"hello","access_token":"[REDACTED]","password":"[REDACTED]","user":"jane","api":"[REDACTED]","g":{"password":"[REDACTED]"},` +
+		`"request":{"headers":{"Accept":["text/plain"],"Authorization":"[REDACTED]"}},"headers":{"Accept":["text/plain"],"Authorization":["Bearer abc"]}}` + "\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestRedactDefaults(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := New(Chain(slog.NewTextHandler(b, testopts), Redact(nil)))
+	log.Info("hello", "Authorization", "Bearer abc", "GITHUB_TOKEN", "ghp_abc", "name", "x")
+
+	want := "level=INFO msg=hello Authorization=[REDACTED] GITHUB_TOKEN=[REDACTED] name=x\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRedactReplaceAttr(t *testing.T) {
+	b := new(bytes.Buffer)
+	replace := RedactReplaceAttr(&RedactOptions{Keys: []string{"g.password"}, Placeholder: "***"})
+	log := New(slog.NewTextHandler(b, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			return replace(groups, testopts.ReplaceAttr(groups, a))
+		},
+	}))
+	log.Info("hello", "password", "a", slog.Group("g", "password", "b"))
+
+	want := "level=INFO msg=hello password=a g.password=***\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	s := NewSecret("hunter2")
+	if got := s.Value(); got != "hunter2" {
+		t.Errorf("want hunter2, got %q", got)
+	}
+	for _, format := range []string{"%v", "%s", "%#v", "%+v", "%d", "%x", "%q", "%10.2f"} {
+		for _, v := range []any{s, NewSecret(42)} {
+			if got := fmt.Sprintf(format, v); got != redactedPlaceholder {
+				t.Errorf("%s: want %q, got %q", format, redactedPlaceholder, got)
+			}
+		}
+	}
+	type config struct {
+		User     string
+		Password Secret[string]
+	}
+	if want, got := "{User:jane Password:[REDACTED]}", fmt.Sprintf("%+v", config{"jane", s}); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	j, err := json.Marshal(map[string]any{"s": s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := `{"s":"[REDACTED]"}`, string(j); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+
+	b := new(bytes.Buffer)
+	New(slog.NewTextHandler(b, testopts)).Info("hello", "s", s)
+	if want, got := "level=INFO msg=hello s=[REDACTED]\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,218 @@
+package clog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Predicate reports whether a record should be sent to a [Route].
+// The record includes the attrs added to the handler with WithAttrs,
+// nested in any groups.
+type Predicate func(ctx context.Context, r slog.Record) bool
+
+// Route is a destination of a [Router].
+type Route struct {
+	// Handler is the handler records are sent to.
+	Handler slog.Handler
+
+	// Match selects the records sent to Handler. If nil, all records are sent.
+	Match Predicate
+}
+
+// Router is a slog.Handler that sends each record to every route that
+// matches it and whose handler is enabled for its level.
+type Router struct {
+	routes []Route
+	// root are the routes given to NewRouter, and scope records the groups
+	// and attrs applied to them since.
+	root  []Route
+	scope []scope
+}
+
+// NewRouter returns a handler that sends records to the matching routes.
+func NewRouter(routes ...Route) *Router {
+	routes = slices.Clone(routes)
+	return &Router{routes: routes, root: routes}
+}
+
+// NewFanout returns a handler that sends every record to all of handlers.
+func NewFanout(handlers ...slog.Handler) *Router {
+	routes := make([]Route, len(handlers))
+	for i, h := range handlers {
+		routes[i] = Route{Handler: h}
+	}
+	return &Router{routes: routes, root: routes}
+}
+
+func (h *Router) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, r := range h.routes {
+		if enabled(ctx, r.Handler, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Router) Handle(ctx context.Context, r slog.Record) error {
+	var (
+		match slog.Record
+		built bool
+		errs  []error
+	)
+	for _, route := range h.routes {
+		if !enabled(ctx, route.Handler, r.Level) {
+			continue
+		}
+		if route.Match != nil {
+			if !built {
+				match, built = scopeRecord(h.scope, r), true
+			}
+			if !route.Match(ctx, match) {
+				continue
+			}
+		}
+		if err := route.Handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *Router) WithAttrs(attrs []slog.Attr) slog.Handler {
+	routes := make([]Route, len(h.routes))
+	for i, r := range h.routes {
+		routes[i] = Route{Handler: r.Handler.WithAttrs(attrs), Match: r.Match}
+	}
+	return &Router{routes: routes, root: h.root, scope: append(slices.Clip(h.scope), scope{attrs: attrs})}
+}
+
+func (h *Router) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	routes := make([]Route, len(h.routes))
+	for i, r := range h.routes {
+		routes[i] = Route{Handler: r.Handler.WithGroup(name), Match: r.Match}
+	}
+	return &Router{routes: routes, root: h.root, scope: append(slices.Clip(h.scope), scope{group: name})}
+}
+
+// Flush implements [Flusher] by flushing each route's handler.
+func (h *Router) Flush(ctx context.Context) error {
+	var errs []error
+	for _, r := range h.root {
+		errs = append(errs, Flush(ctx, r.Handler))
+	}
+	return errors.Join(errs...)
+}
+
+// Close implements [Closer] by closing each route's handler.
+func (h *Router) Close(ctx context.Context) error {
+	var errs []error
+	for _, r := range h.root {
+		errs = append(errs, Close(ctx, r.Handler))
+	}
+	return errors.Join(errs...)
+}
+
+// MatchLevel matches records at or above level.
+func MatchLevel(level slog.Leveler) Predicate {
+	return func(_ context.Context, r slog.Record) bool {
+		return r.Level >= level.Level()
+	}
+}
+
+// MatchLevelBelow matches records below level.
+func MatchLevelBelow(level slog.Leveler) Predicate {
+	return func(_ context.Context, r slog.Record) bool {
+		return r.Level < level.Level()
+	}
+}
+
+// MatchMessage matches records whose message satisfies f.
+func MatchMessage(f func(msg string) bool) Predicate {
+	return func(_ context.Context, r slog.Record) bool {
+		return f(r.Message)
+	}
+}
+
+// MatchSource matches records whose source location satisfies f.
+// Records without a source location don't match.
+func MatchSource(f func(*slog.Source) bool) Predicate {
+	return func(_ context.Context, r slog.Record) bool {
+		if r.PC == 0 {
+			return false
+		}
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		return f(&slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line})
+	}
+}
+
+// MatchAttr matches records with an attr whose value satisfies f.
+// Keys in groups are joined with dots, e.g. "request.method".
+// If f is nil, any record with the attr matches.
+func MatchAttr(key string, f func(slog.Value) bool) Predicate {
+	path := strings.Split(key, ".")
+	return func(_ context.Context, r slog.Record) bool {
+		found := false
+		r.Attrs(func(a slog.Attr) bool {
+			found = matchAttr(a, path, f)
+			return !found
+		})
+		return found
+	}
+}
+
+// matchAttr reports whether a, or an attr in it, has the given path and a value satisfying f.
+func matchAttr(a slog.Attr, path []string, f func(slog.Value) bool) bool {
+	if a.Key != path[0] {
+		// Attrs of groups with empty keys are inlined.
+		if a.Key != "" || a.Value.Kind() != slog.KindGroup {
+			return false
+		}
+		return slices.ContainsFunc(a.Value.Group(), func(a slog.Attr) bool { return matchAttr(a, path, f) })
+	}
+	v := a.Value.Resolve()
+	if len(path) > 1 {
+		if v.Kind() != slog.KindGroup {
+			return false
+		}
+		return slices.ContainsFunc(v.Group(), func(a slog.Attr) bool { return matchAttr(a, path[1:], f) })
+	}
+	return f == nil || f(v)
+}
+
+// MatchAll matches records that match all of ps.
+func MatchAll(ps ...Predicate) Predicate {
+	return func(ctx context.Context, r slog.Record) bool {
+		for _, p := range ps {
+			if !p(ctx, r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchAny matches records that match any of ps.
+func MatchAny(ps ...Predicate) Predicate {
+	return func(ctx context.Context, r slog.Record) bool {
+		for _, p := range ps {
+			if p(ctx, r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MatchNot matches records that don't match p.
+func MatchNot(p Predicate) Predicate {
+	return func(ctx context.Context, r slog.Record) bool {
+		return !p(ctx, r)
+	}
+}
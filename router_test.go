@@ -0,0 +1,131 @@
+package clog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// errorHandler is a handler that fails to handle records.
+type errorHandler struct {
+	slog.Handler
+	err error
+}
+
+func (h errorHandler) Handle(context.Context, slog.Record) error {
+	return h.err
+}
+
+func TestRouter(t *testing.T) {
+	errs := new(syncBuffer)
+	all := new(syncBuffer)
+	audit := new(syncBuffer)
+	h := NewRouter(
+		Route{
+			Handler: slog.NewTextHandler(errs, testopts),
+			Match:   MatchLevel(slog.LevelError),
+		},
+		Route{
+			Handler: slog.NewTextHandler(all, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: testopts.ReplaceAttr}),
+			Match:   MatchNot(MatchAttr("audit", nil)),
+		},
+		Route{
+			Handler: slog.NewTextHandler(audit, testopts),
+			Match:   MatchAttr("audit", func(v slog.Value) bool { return v.Bool() }),
+		},
+	)
+	log := New(h)
+
+	ctx := WithValues(context.Background(), "a", "b")
+	log.DebugContext(ctx, "debug")
+	log.ErrorContext(ctx, "error")
+	// Attrs added with With are visible to predicates.
+	log.With("audit", true).InfoContext(ctx, "audit")
+
+	for _, tc := range []struct {
+		name string
+		b    *syncBuffer
+		want string
+	}{
+		{"errors", errs, "level=ERROR msg=error a=b\n"},
+		{"all", all, "level=DEBUG msg=debug a=b\nlevel=ERROR msg=error a=b\n"},
+		{"audit", audit, "level=INFO msg=audit audit=true a=b\n"},
+	} {
+		if got := tc.b.String(); got != tc.want {
+			t.Errorf("%s: want %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestRouterEnabled(t *testing.T) {
+	h := NewFanout(
+		slog.NewTextHandler(new(syncBuffer), &slog.HandlerOptions{Level: slog.LevelWarn}),
+		slog.NewTextHandler(new(syncBuffer), &slog.HandlerOptions{Level: slog.LevelInfo}),
+	)
+	ctx := context.Background()
+	if h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("want debug disabled")
+	}
+	if !h.Enabled(ctx, slog.LevelInfo) {
+		t.Error("want info enabled")
+	}
+}
+
+func TestRouterErrors(t *testing.T) {
+	err1, err2 := errors.New("one"), errors.New("two")
+	b := new(syncBuffer)
+	h := NewFanout(
+		errorHandler{Handler: slog.NewTextHandler(b, nil), err: err1},
+		slog.NewTextHandler(b, testopts),
+		errorHandler{Handler: slog.NewTextHandler(b, nil), err: err2},
+	)
+	err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "hello", 0))
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Errorf("want both errors, got %v", err)
+	}
+	if want, got := "level=INFO msg=hello\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestMatchAttr(t *testing.T) {
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "hello", 0)
+	r.AddAttrs(slog.Group("request", slog.String("method", "GET")), slog.Group("", slog.Int("n", 1)))
+	ctx := context.Background()
+	for _, tc := range []struct {
+		p    Predicate
+		want bool
+	}{
+		{MatchAttr("request.method", func(v slog.Value) bool { return v.String() == "GET" }), true},
+		{MatchAttr("request.method", func(v slog.Value) bool { return v.String() == "POST" }), false},
+		{MatchAttr("request", nil), true},
+		{MatchAttr("method", nil), false},
+		{MatchAttr("n", nil), true},
+		{MatchAll(MatchAttr("n", nil), MatchMessage(func(m string) bool { return strings.HasPrefix(m, "he") })), true},
+		{MatchAny(MatchAttr("x", nil), MatchLevelBelow(slog.LevelInfo)), false},
+		{MatchSource(func(*slog.Source) bool { return true }), false},
+	} {
+		if got := tc.p(ctx, r); got != tc.want {
+			t.Errorf("want %v, got %v", tc.want, got)
+		}
+	}
+}
+
+func TestRouterWithLevel(t *testing.T) {
+	b := new(syncBuffer)
+	h := NewRouter(Route{Handler: slog.NewTextHandler(b, testopts)})
+	log := New(h)
+
+	ctx := WithLevel(context.Background(), slog.LevelDebug)
+	if !h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("want debug enabled with WithLevel")
+	}
+	log.DebugContext(ctx, "one")
+	log.DebugContext(context.Background(), "two")
+	if want, got := "level=DEBUG msg=one\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,204 @@
+package clog
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultSampleInterval is the interval used if [SamplerOptions.Interval] is not set.
+const defaultSampleInterval = time.Second
+
+// SamplerOptions are options for a [Sampler].
+// A zero SamplerOptions keeps every record.
+type SamplerOptions struct {
+	// First is the number of records with the same level and message that are
+	// kept in each interval. After that, every Thereafter-th record is kept.
+	// If both are zero, records are not counted.
+	First      int
+	Thereafter int
+
+	// Interval is how often the counts are reset. Defaults to one second.
+	Interval time.Duration
+
+	// Rates is the fraction of records kept at each level, from 0 to 1.
+	// A record uses the rate of the highest level at or below its own;
+	// records below every configured level are always kept.
+	Rates map[slog.Level]float64
+
+	// Always is the level at or above which records are never sampled.
+	// Defaults to LevelError.
+	Always slog.Leveler
+
+	// Key returns the sampling key for a record's context, such as a trace ID
+	// (e.g. gcp.TraceFromContext). Records with the same key are consistently
+	// kept or dropped together, and are not counted.
+	Key func(ctx context.Context) string
+
+	// ContextKey, if Key is not set, is the key of a context value, set with
+	// [WithValues] or an [Extractor], to use as the sampling key.
+	ContextKey string
+}
+
+// Sampler is a slog.Handler that drops a portion of the records it handles,
+// to reduce the volume of high-traffic logs.
+type Sampler struct {
+	h slog.Handler
+	s *sampler
+}
+
+// sampler is the state shared by a Sampler and the handlers derived from it.
+type sampler struct {
+	h    slog.Handler
+	opts SamplerOptions
+
+	dropped atomic.Uint64
+
+	mu     sync.Mutex
+	start  time.Time
+	counts map[sampleKey]int
+}
+
+type sampleKey struct {
+	level   slog.Level
+	message string
+}
+
+// NewSampler returns a handler that writes a sample of records to h.
+// If opts is nil, every record is kept.
+func NewSampler(h slog.Handler, opts *SamplerOptions) *Sampler {
+	if opts == nil {
+		opts = &SamplerOptions{}
+	}
+	return &Sampler{h: h, s: &sampler{
+		h:      h,
+		opts:   *opts,
+		counts: map[sampleKey]int{},
+	}}
+}
+
+func (h *Sampler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+func (h *Sampler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.s.keep(ctx, r) {
+		h.s.dropped.Add(1)
+		return nil
+	}
+	return h.h.Handle(ctx, r)
+}
+
+func (h *Sampler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Sampler{h: h.h.WithAttrs(attrs), s: h.s}
+}
+
+func (h *Sampler) WithGroup(name string) slog.Handler {
+	return &Sampler{h: h.h.WithGroup(name), s: h.s}
+}
+
+// Dropped returns the number of records dropped by sampling.
+func (h *Sampler) Dropped() uint64 {
+	return h.s.dropped.Load()
+}
+
+// Flush implements [Flusher] by flushing the inner handler.
+func (h *Sampler) Flush(ctx context.Context) error {
+	return Flush(ctx, h.s.h)
+}
+
+// Close implements [Closer] by closing the inner handler.
+func (h *Sampler) Close(ctx context.Context) error {
+	return Close(ctx, h.s.h)
+}
+
+// keep reports whether r should be written.
+func (s *sampler) keep(ctx context.Context, r slog.Record) bool {
+	always := LevelError
+	if s.opts.Always != nil {
+		always = s.opts.Always.Level()
+	}
+	if r.Level >= always {
+		return true
+	}
+
+	key := s.key(ctx)
+	if rate, ok := s.rate(r.Level); ok {
+		var n float64
+		if key != "" {
+			n = fraction(key)
+		} else {
+			n = rand.Float64()
+		}
+		if n >= rate {
+			return false
+		}
+	}
+	if key != "" || (s.opts.First == 0 && s.opts.Thereafter == 0) {
+		return true
+	}
+	return s.count(r)
+}
+
+// key returns the sampling key for ctx, if any.
+func (s *sampler) key(ctx context.Context) string {
+	if s.opts.Key != nil {
+		return s.opts.Key(ctx)
+	}
+	if s.opts.ContextKey == "" {
+		return ""
+	}
+	if v, ok := contextValue(ctx, s.opts.ContextKey); ok {
+		return v.String()
+	}
+	return ""
+}
+
+// rate returns the rate for level, if one applies.
+func (s *sampler) rate(level slog.Level) (float64, bool) {
+	var (
+		rate  float64
+		found bool
+		best  slog.Level
+	)
+	for l, r := range s.opts.Rates {
+		if l <= level && (!found || l > best) {
+			rate, found, best = r, true, l
+		}
+	}
+	return rate, found
+}
+
+// count counts r and reports whether it is within the first/thereafter limits.
+func (s *sampler) count(r slog.Record) bool {
+	now := recordTime(r)
+	interval := s.opts.Interval
+	if interval <= 0 {
+		interval = defaultSampleInterval
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.start) >= interval || now.Before(s.start) {
+		s.start = now
+		clear(s.counts)
+	}
+	k := sampleKey{level: r.Level, message: r.Message}
+	n := s.counts[k]
+	s.counts[k] = n + 1
+	if n < s.opts.First {
+		return true
+	}
+	return s.opts.Thereafter > 0 && (n-s.opts.First)%s.opts.Thereafter == 0
+}
+
+// fraction maps key to a number in [0, 1), the same for every call.
+func fraction(key string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
@@ -0,0 +1,126 @@
+package clog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type traceKey struct{}
+
+func TestSamplerCounts(t *testing.T) {
+	b := new(syncBuffer)
+	h := NewSampler(slog.NewTextHandler(b, testopts), &SamplerOptions{
+		First:      2,
+		Thereafter: 3,
+		Interval:   time.Minute,
+	})
+	ctx := context.Background()
+	start := time.Now()
+	log := func(offset time.Duration, level slog.Level, msg string, i int) {
+		r := slog.NewRecord(start.Add(offset), level, msg, 0)
+		r.AddAttrs(slog.Int("i", i))
+		if err := h.Handle(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range 8 {
+		log(0, slog.LevelInfo, "a", i)
+	}
+	log(0, slog.LevelInfo, "b", 0)
+	log(0, slog.LevelError, "a", 0)
+	// The counts are reset after the interval.
+	log(time.Minute, slog.LevelInfo, "a", 8)
+
+	want := []string{
+		"level=INFO msg=a i=0",
+		"level=INFO msg=a i=1",
+		"level=INFO msg=a i=2",
+		"level=INFO msg=a i=5",
+		"level=INFO msg=b i=0",
+		"level=ERROR msg=a i=0",
+		"level=INFO msg=a i=8",
+	}
+	if got := strings.Split(strings.TrimSpace(b.String()), "\n"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got := h.Dropped(); got != 4 {
+		t.Errorf("want 4 dropped, got %d", got)
+	}
+}
+
+func TestSamplerRates(t *testing.T) {
+	b := new(syncBuffer)
+	log := New(NewSampler(slog.NewTextHandler(b, testopts), &SamplerOptions{
+		Rates: map[slog.Level]float64{
+			slog.LevelDebug: 0,
+			slog.LevelInfo:  1,
+		},
+	}))
+	log.Trace("trace")
+	log.Debug("debug")
+	log.Info("info")
+	log.Warn("warn")
+	log.Error("error")
+
+	want := "level=INFO msg=info\nlevel=WARN msg=warn\nlevel=ERROR msg=error\n"
+	if got := b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestSamplerKey(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts SamplerOptions
+		ctx  func(context.Context, string) context.Context
+	}{{
+		name: "key",
+		opts: SamplerOptions{Key: func(ctx context.Context) string {
+			id, _ := ctx.Value(traceKey{}).(string)
+			return id
+		}},
+		ctx: func(ctx context.Context, id string) context.Context {
+			return context.WithValue(ctx, traceKey{}, id)
+		},
+	}, {
+		name: "context key",
+		opts: SamplerOptions{ContextKey: "trace"},
+		ctx: func(ctx context.Context, id string) context.Context {
+			return WithValues(ctx, "trace", id)
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(syncBuffer)
+			opts := tc.opts
+			opts.Rates = map[slog.Level]float64{slog.LevelInfo: 0.5}
+			// Counting doesn't apply to records with a sampling key.
+			opts.First = 1
+			log := New(NewSampler(slog.NewTextHandler(b, testopts), &opts))
+
+			kept := 0
+			for i := range 100 {
+				id := fmt.Sprint("trace-", i)
+				ctx := tc.ctx(context.Background(), id)
+				before := b.String()
+				log.InfoContext(ctx, "one")
+				log.InfoContext(ctx, "two")
+				log.ErrorContext(ctx, "three")
+				got := strings.Count(b.String()[len(before):], "\n")
+				switch got {
+				case 3:
+					kept++
+				case 1:
+				default:
+					t.Errorf("%s: want all or none of the info records, got %d records", id, got)
+				}
+			}
+			if kept == 0 || kept == 100 {
+				t.Errorf("want some traces kept and some dropped, got %d kept", kept)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package clog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ScanPattern is a shape of sensitive value found by [Scan].
+type ScanPattern struct {
+	// Name identifies the pattern in placeholders and [ScanHit]s.
+	Name string
+	// Regexp matches the sensitive values.
+	Regexp *regexp.Regexp
+}
+
+// DefaultScanPatterns are the patterns used if [ScanOptions.Patterns] is empty.
+var DefaultScanPatterns = []ScanPattern{
+	{Name: "jwt", Regexp: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	{Name: "github_token", Regexp: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Name: "gcp_api_key", Regexp: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{Name: "private_key", Regexp: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{Name: "email", Regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)},
+}
+
+// ScanHit describes a sensitive value found by [Scan].
+type ScanHit struct {
+	// Pattern is the name of the pattern that matched.
+	Pattern string
+	// Key is the key of the attr the value was found in, with any groups
+	// joined with dots, or empty if it was found in the message.
+	Key string
+	// Message is the message of the record the value was found in.
+	// It has been masked.
+	Message string
+}
+
+// ScanOptions are options for [Scan].
+// A zero ScanOptions consists entirely of default values.
+type ScanOptions struct {
+	// Patterns are the shapes of value to mask.
+	// Defaults to [DefaultScanPatterns].
+	Patterns []ScanPattern
+
+	// Placeholder replaces each value found. By default, values are replaced
+	// with "[REDACTED:<name>]", where <name> is the pattern's name.
+	Placeholder string
+
+	// OnHit, if set, is called for each value found.
+	OnHit func(ctx context.Context, hit ScanHit)
+}
+
+// Scan returns middleware, for use with [Chain], that masks values that look
+// like secrets or personal information, such as tokens and email addresses,
+// in the record's message and string attrs.
+//
+// Unlike [Redact], this finds values whatever their key, including in
+// messages formatted by Infof and friends. Scanning is more expensive than
+// redaction, since every string is matched against every pattern.
+// If opts is nil, the default options are used.
+func Scan(opts *ScanOptions) Middleware {
+	if opts == nil {
+		opts = &ScanOptions{}
+	}
+	s := &scanner{opts: *opts}
+	if len(s.opts.Patterns) == 0 {
+		s.opts.Patterns = DefaultScanPatterns
+	}
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, r slog.Record) error {
+			var hits []ScanHit
+			msg := s.mask(r.Message, "", &hits)
+			nr := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+			nr.AddAttrs(s.attrs(nil, recordAttrs(r), &hits)...)
+			if s.opts.OnHit != nil {
+				for _, hit := range hits {
+					hit.Message = msg
+					s.opts.OnHit(ctx, hit)
+				}
+			}
+			return next(ctx, nr)
+		}
+	}
+}
+
+type scanner struct {
+	opts ScanOptions
+}
+
+func (s *scanner) attrs(groups []string, attrs []slog.Attr, hits *[]ScanHit) []slog.Attr {
+	scanned := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		scanned[i] = s.attr(groups, a, hits)
+	}
+	return scanned
+}
+
+// attr returns a with any sensitive values in it masked.
+func (s *scanner) attr(groups []string, a slog.Attr, hits *[]ScanHit) slog.Attr {
+	v := a.Value.Resolve()
+	p := groups
+	if a.Key != "" {
+		p = append(slices.Clip(groups), a.Key)
+	}
+	key := strings.Join(p, ".")
+	switch v.Kind() {
+	case slog.KindGroup:
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(s.attrs(p, v.Group(), hits)...)}
+	case slog.KindString:
+		return slog.String(a.Key, s.mask(v.String(), key, hits))
+	case slog.KindAny:
+		// Errors and Stringers are logged as strings, so they can contain secrets too.
+		var str string
+		switch x := v.Any().(type) {
+		case error:
+			str = x.Error()
+		case fmt.Stringer:
+			str = x.String()
+		default:
+			return slog.Attr{Key: a.Key, Value: v}
+		}
+		if masked := s.mask(str, key, hits); masked != str {
+			return slog.String(a.Key, masked)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// mask returns str with the values matching any pattern replaced,
+// adding a hit for each to hits.
+func (s *scanner) mask(str, key string, hits *[]ScanHit) string {
+	for _, p := range s.opts.Patterns {
+		placeholder := s.opts.Placeholder
+		if placeholder == "" {
+			placeholder = "[REDACTED:" + p.Name + "]"
+		}
+		str = p.Regexp.ReplaceAllStringFunc(str, func(string) string {
+			*hits = append(*hits, ScanHit{Pattern: p.Name, Key: key})
+			return placeholder
+		})
+	}
+	return str
+}
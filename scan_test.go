@@ -0,0 +1,80 @@
+package clog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	// Build the values at runtime so they don't look like secrets in the source.
+	jwt := "eyJ" + "hbGciOiJIUzI1NiJ9.eyJ" + "zdWIiOiIxMjM0In0.sig_nature"
+	gh := "ghp_" + strings.Repeat("a1", 18)
+	apiKey := "AIza" + strings.Repeat("B", 35)
+	pem := "-----BEGIN RSA " + "PRIVATE KEY-----\nMIIB\n-----END RSA " + "PRIVATE KEY-----"
+
+	for _, tc := range []struct {
+		name string
+		log  func(*Logger)
+		want string
+		hits []ScanHit
+	}{{
+		name: "message",
+		log:  func(l *Logger) { l.Infof("token %s for jane@example.com", jwt) },
+		want: `level=INFO msg="token [REDACTED:jwt] for [REDACTED:email]"`,
+		hits: []ScanHit{{Pattern: "jwt"}, {Pattern: "email"}},
+	}, {
+		name: "attrs",
+		log: func(l *Logger) {
+			l.With("key", apiKey).Info("hello", slog.Group("g", "pem", pem), "n", 1, "err", errors.New("bad token "+gh))
+		},
+		want: `level=INFO msg=hello key=[REDACTED:gcp_api_key] g.pem=[REDACTED:private_key] n=1 err="bad token [REDACTED:github_token]"`,
+		hits: []ScanHit{{Pattern: "gcp_api_key", Key: "key"}, {Pattern: "private_key", Key: "g.pem"}, {Pattern: "github_token", Key: "err"}},
+	}, {
+		name: "context",
+		log: func(l *Logger) {
+			l.InfoContext(WithValues(context.Background(), "user", "jane@example.com"), "hello")
+		},
+		want: `level=INFO msg=hello user=[REDACTED:email]`,
+		hits: []ScanHit{{Pattern: "email", Key: "user"}},
+	}, {
+		name: "none",
+		log:  func(l *Logger) { l.Info("hello", "a", "b") },
+		want: `level=INFO msg=hello a=b`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			var hits []ScanHit
+			log := New(Chain(slog.NewTextHandler(b, testopts), Scan(&ScanOptions{
+				OnHit: func(_ context.Context, hit ScanHit) {
+					hit.Message = ""
+					hits = append(hits, hit)
+				},
+			})))
+			tc.log(log)
+			if got := strings.TrimSpace(b.String()); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+			if len(hits) != len(tc.hits) {
+				t.Fatalf("want hits %v, got %v", tc.hits, hits)
+			}
+			for i := range hits {
+				if hits[i] != tc.hits[i] {
+					t.Errorf("want hits %v, got %v", tc.hits, hits)
+				}
+			}
+		})
+	}
+}
+
+func TestScanPlaceholder(t *testing.T) {
+	b := new(bytes.Buffer)
+	log := New(Chain(slog.NewTextHandler(b, testopts), Scan(&ScanOptions{Placeholder: "***"})))
+	log.Info("mail jane@example.com")
+	if want, got := "level=INFO msg=\"mail ***\"\n", b.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
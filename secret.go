@@ -0,0 +1,52 @@
+package clog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// redactedPlaceholder is the value logged in place of redacted values.
+const redactedPlaceholder = "[REDACTED]"
+
+// Secret holds a value that must never be logged, such as a password or
+// token. It is logged, and formatted with fmt using any verb, as "[REDACTED]".
+type Secret[T any] struct {
+	v T
+}
+
+// NewSecret returns a Secret holding v.
+func NewSecret[T any](v T) Secret[T] {
+	return Secret[T]{v: v}
+}
+
+// Value returns the value held by s.
+func (s Secret[T]) Value() T {
+	return s.v
+}
+
+// LogValue implements [slog.LogValuer].
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(redactedPlaceholder)
+}
+
+// String implements [fmt.Stringer].
+func (s Secret[T]) String() string {
+	return redactedPlaceholder
+}
+
+// GoString implements [fmt.GoStringer].
+func (s Secret[T]) GoString() string {
+	return redactedPlaceholder
+}
+
+// Format implements [fmt.Formatter], so every verb prints the placeholder.
+func (s Secret[T]) Format(f fmt.State, _ rune) {
+	io.WriteString(f, redactedPlaceholder)
+}
+
+// MarshalJSON implements [json.Marshaler].
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redactedPlaceholder)
+}
@@ -27,20 +27,33 @@
 //	}
 package slag
 
-import "log/slog"
+import (
+	"log/slog"
 
+	"github.com/chainguard-dev/clog"
+)
+
+// Level is a [slog.Level] that can be set from the command line.
+// In addition to the [slog] levels, it accepts the levels defined by clog
+// (e.g. "trace", "notice", "critical") and offsets such as "info+2".
 type Level slog.Level
 
 func (l *Level) Set(s string) error {
-	var ll slog.Level
-	if err := ll.UnmarshalText([]byte(s)); err != nil {
+	ll, err := clog.ParseLevel(s)
+	if err != nil {
 		return err
 	}
 	*l = Level(ll)
 	return nil
 }
-func (l *Level) String() string    { return slog.Level(*l).String() }
+func (l *Level) String() string    { return clog.LevelString(slog.Level(*l)) }
 func (l *Level) Level() slog.Level { return slog.Level(*l) }
 
+// MarshalText implements [encoding.TextMarshaler] using [clog.LevelString].
+func (l Level) MarshalText() ([]byte, error) { return []byte(clog.LevelString(slog.Level(l))), nil }
+
+// UnmarshalText implements [encoding.TextUnmarshaler] using [clog.ParseLevel].
+func (l *Level) UnmarshalText(b []byte) error { return l.Set(string(b)) }
+
 // Implements https://pkg.go.dev/github.com/spf13/pflag#Value
 func (l *Level) Type() string { return "string" }
@@ -48,17 +48,47 @@ type Logger interface {
 
 // TestLogger gets a logger to use in unit and end to end tests.
 // This logger is configured to log at debug level.
+// Calling Fatal on this logger panics with [Exit] instead of exiting;
+// see [CatchFatal].
 func TestLogger(t Logger) *clog.Logger {
-	return clog.New(slog.NewTextHandler(&logAdapter{l: t}, &slog.HandlerOptions{
+	return TestLoggerWithOptions(t, &slog.HandlerOptions{
 		Level:       slog.LevelDebug,
 		AddSource:   true,
 		ReplaceAttr: RemoveTime,
-	}))
+	})
 }
 
 // TestLoggerWithOptions gets a logger to use in unit and end to end tests.
+// Calling Fatal on this logger panics with [Exit] instead of exiting;
+// see [CatchFatal].
 func TestLoggerWithOptions(t Logger, opts *slog.HandlerOptions) *clog.Logger {
-	return clog.New(slog.NewTextHandler(&logAdapter{l: t}, opts))
+	return clog.New(slog.NewTextHandler(&logAdapter{l: t}, opts)).WithExitFunc(clog.PanicExit)
+}
+
+// Exit is the value loggers from this package panic with when Fatal is called.
+type Exit = clog.Exit
+
+// CatchFatal calls fn, and reports whether it called Fatal (or one of its
+// variants) on a logger from this package, and with what exit code.
+//
+//	func TestFatal(t *testing.T) {
+//		ctx := slogtest.Context(t)
+//		if _, fatal := slogtest.CatchFatal(func() { run(ctx) }); !fatal {
+//			t.Error("want Fatal to be called")
+//		}
+//	}
+func CatchFatal(fn func()) (code int, fatal bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(Exit)
+			if !ok {
+				panic(r)
+			}
+			code, fatal = e.Code, true
+		}
+	}()
+	fn()
+	return 0, false
 }
 
 // Context returns a context with a logger to be used in tests.
@@ -39,3 +39,37 @@ func TestSlogTestTContext(t *testing.T) {
 
 	fn(ctx)
 }
+
+func TestCatchFatal(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	if code, fatal := slogtest.CatchFatal(func() {
+		clog.FatalContext(ctx, "goodbye")
+		t.Error("want Fatal not to return")
+	}); !fatal || code != 1 {
+		t.Errorf("want fatal with code 1, got %t %d", fatal, code)
+	}
+
+	if code, fatal := slogtest.CatchFatal(func() {
+		clog.FromContext(ctx).WithExitCode(3).Fatalf("goodbye %s", "world")
+	}); !fatal || code != 3 {
+		t.Errorf("want fatal with code 3, got %t %d", fatal, code)
+	}
+
+	if _, fatal := slogtest.CatchFatal(func() {
+		clog.InfoContext(ctx, "hello")
+	}); fatal {
+		t.Error("want no fatal")
+	}
+}
+
+func TestCatchFatalRecover(t *testing.T) {
+	ctx := slogtest.TestContextWithLogger(t)
+	code, fatal := slogtest.CatchFatal(func() {
+		defer clog.Recover(ctx)
+		clog.FromContext(ctx).Fatal("bye")
+	})
+	if !fatal || code != 1 {
+		t.Errorf("want fatal with code 1, got %v, %d", fatal, code)
+	}
+}